@@ -0,0 +1,330 @@
+package himago
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// archiveIndex is the name of the index file in the root of an Archive.
+const archiveIndex = "index.jsonl"
+
+// Archive is a managed store of images laid out by band, zoom and time as
+//
+//	root/band/zoom/YYYY/MM/DD/HHMM.png
+//
+// along with an index, root/index.jsonl, with one ArchiveEntry per line.
+// Entries are only ever appended to the index, the last entry for a path
+// wins, until Prune rewrites it.
+type Archive struct {
+	Root string
+
+	mu sync.Mutex
+}
+
+// ArchiveEntry describes a single image in an Archive.
+type ArchiveEntry struct {
+	Time time.Time `json:"time"`
+	Band Band      `json:"band"`
+	Zoom Zoom      `json:"zoom"`
+
+	// Path is relative to the root of the Archive, with forward slashes.
+	Path string `json:"path"`
+
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ArchiveQuery selects entries from an Archive. Zero fields match
+// everything.
+type ArchiveQuery struct {
+	Bands []Band
+	Zoom  Zoom
+
+	// From and To bound the image time, inclusive.
+	From time.Time
+	To   time.Time
+}
+
+// OpenArchive returns the Archive rooted at root, creating the directory if
+// it does not already exist.
+func OpenArchive(root string) (*Archive, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Archive{Root: root}, nil
+}
+
+// archivePath returns the path of an image relative to the root.
+func archivePath(band Band, zoom Zoom, t SatTime) string {
+	t.Time = t.UTC()
+	return fmt.Sprintf("%d/%d/%s.png", int(band), int(zoom), t.Format("2006/01/02/1504"))
+}
+
+// Path returns the file an image is, or would be, stored in.
+func (a *Archive) Path(band Band, zoom Zoom, t SatTime) string {
+	return filepath.Join(a.Root, filepath.FromSlash(archivePath(band, zoom, t)))
+}
+
+// Put stitches the Tiles of a Capture over bg and stores the image, with
+// embedded Metadata, at the capture's time, replacing any existing image.
+func (a *Archive) Put(c *Capture, bg Background, fg Color) (ArchiveEntry, error) {
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, StitchBackground(c.Band, c.Tiles, bg, fg), NewMetadata(c, bg, fg)); err != nil {
+		return ArchiveEntry{}, err
+	}
+
+	entry := ArchiveEntry{
+		Time:   c.Time.UTC(),
+		Band:   c.Band,
+		Zoom:   c.Zoom,
+		Path:   archivePath(c.Band, c.Zoom, c.Time),
+		Size:   int64(buf.Len()),
+		SHA256: fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+	}
+
+	fileName := a.Path(c.Band, c.Zoom, c.Time)
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return entry, err
+	}
+
+	if err := os.WriteFile(fileName, buf.Bytes(), 0644); err != nil {
+		return entry, err
+	}
+
+	return entry, a.append(entry)
+}
+
+// append adds an entry to the end of the index.
+func (a *Archive) append(entry ArchiveEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(a.Root, archiveIndex), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// List returns every entry in the index ordered by time, band and zoom.
+func (a *Archive) List() ([]ArchiveEntry, error) {
+	return a.Query(ArchiveQuery{})
+}
+
+// Query returns the entries matching q ordered by time, band and zoom.
+func (a *Archive) Query(q ArchiveQuery) ([]ArchiveEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.query(q)
+}
+
+// query is Query without locking, for callers already holding a.mu.
+func (a *Archive) query(q ArchiveQuery) ([]ArchiveEntry, error) {
+	f, err := os.Open(filepath.Join(a.Root, archiveIndex))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	byPath, err := readArchiveIndex(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ArchiveEntry
+	for _, e := range byPath {
+		if q.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		if a.Band != b.Band {
+			return a.Band < b.Band
+		}
+		return a.Zoom < b.Zoom
+	})
+
+	return entries, nil
+}
+
+// readArchiveIndex returns the last entry for every path in an index.
+func readArchiveIndex(r io.Reader) (map[string]ArchiveEntry, error) {
+	byPath := map[string]ArchiveEntry{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var e ArchiveEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid archive index on line %v: %v", line, err)
+		}
+		byPath[e.Path] = e
+	}
+
+	return byPath, scanner.Err()
+}
+
+func (q ArchiveQuery) matches(e ArchiveEntry) bool {
+	if len(q.Bands) > 0 {
+		found := false
+		for _, b := range q.Bands {
+			found = found || b == e.Band
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if q.Zoom != 0 && q.Zoom != e.Zoom {
+		return false
+	}
+	if !q.From.IsZero() && e.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && e.Time.After(q.To) {
+		return false
+	}
+
+	return true
+}
+
+// Has returns true if the index holds an image for band and zoom at t.
+func (a *Archive) Has(band Band, zoom Zoom, t SatTime) (bool, error) {
+	entries, err := a.Query(ArchiveQuery{Bands: []Band{band}, Zoom: zoom, From: t.Time, To: t.Time})
+	return len(entries) > 0, err
+}
+
+// Verify checks every indexed image against its recorded size and SHA-256
+// hash, returning the entries whose file is missing or does not match.
+func (a *Archive) Verify() ([]ArchiveEntry, error) {
+	entries, err := a.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var bad []ArchiveEntry
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(a.Root, filepath.FromSlash(e.Path)))
+		if os.IsNotExist(err) {
+			bad = append(bad, e)
+			continue
+		}
+		if err != nil {
+			return bad, err
+		}
+
+		if int64(len(data)) != e.Size || fmt.Sprintf("%x", sha256.Sum256(data)) != e.SHA256 {
+			bad = append(bad, e)
+		}
+	}
+
+	return bad, nil
+}
+
+// Prune deletes every image taken before cutoff and rewrites the index
+// without them. Directories left empty are removed. The pruned entries
+// are returned.
+func (a *Archive) Prune(cutoff time.Time) ([]ArchiveEntry, error) {
+	// Hold the lock throughout so that an image added while pruning is
+	// not dropped from the rewritten index
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entries, err := a.query(ArchiveQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	var kept, pruned []ArchiveEntry
+	for _, e := range entries {
+		if e.Time.Before(cutoff) {
+			pruned = append(pruned, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+
+	// Rewrite the index first so it never refers to deleted files
+	var buf bytes.Buffer
+	for _, e := range kept {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	index := filepath.Join(a.Root, archiveIndex)
+	err = os.WriteFile(index+".tmp", buf.Bytes(), 0644)
+	if err == nil {
+		err = os.Rename(index+".tmp", index)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range pruned {
+		fileName := filepath.Join(a.Root, filepath.FromSlash(e.Path))
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+			return pruned, err
+		}
+
+		// Remove empty day, month, year and zoom directories
+		for dir := filepath.Dir(fileName); dir != filepath.Clean(a.Root); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+
+	return pruned, nil
+}
+
+// DownloadToArchive downloads an image and stores it in an Archive
+// instead of a single file. The entry is at the time the image was
+// actually taken, after any rollbacks.
+func (f *Fetcher) DownloadToArchive(a *Archive, band Band, zoom Zoom, imageTime SatTime, bg Background, fg Color) (ArchiveEntry, error) {
+	capture, err := f.GetCapture(band, zoom, imageTime)
+	if err != nil {
+		return ArchiveEntry{}, err
+	}
+
+	return a.Put(capture, bg, fg)
+}
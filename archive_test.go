@@ -0,0 +1,152 @@
+package himago
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestArchive downloads band 13 at zoom 1 every hour from 00:00 to
+// 03:00 on 2017-04-29 into a new Archive.
+func newTestArchive(t *testing.T) *Archive {
+	a, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := newTileServer(t)
+
+	for hour := 0; hour < 4; hour++ {
+		tm := SatTime{time.Date(2017, 4, 29, hour, 5, 0, 0, time.UTC)}
+		if _, err := f.DownloadToArchive(a, Band(13), Zoom(1), tm, Color{}, Color{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return a
+}
+
+// TestArchivePut tests that downloaded images are stored by band, zoom
+// and time and can be found with Has.
+func TestArchivePut(t *testing.T) {
+	a := newTestArchive(t)
+
+	expected := filepath.Join(a.Root, "13", "1", "2017", "04", "29", "0200.png")
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("Expected an image at %v, received %v", expected, err)
+	}
+
+	has, err := a.Has(Band(13), Zoom(1), SatTime{time.Date(2017, 4, 29, 2, 0, 0, 0, time.UTC)})
+	if err != nil || !has {
+		t.Errorf("Expected the archive to have the image, received %v and %v", has, err)
+	}
+}
+
+// TestArchivePutBackground tests that archived images can be drawn over
+// any Background.
+func TestArchivePutBackground(t *testing.T) {
+	a, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := newTileServer(t)
+	white := Color{color.NRGBA{255, 255, 255, 255}}
+	bg := LinearGradient{Stops: Gradient(white, white)}
+
+	entry, err := f.DownloadToArchive(a, Band(13), Zoom(1), SatTime{time.Date(2017, 4, 29, 2, 0, 0, 0, time.UTC)}, bg, white)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(a.Root, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	meta, err := ReadMetadata(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.BG != "linear-gradient" {
+		t.Errorf("Expected \"linear-gradient\", received %q", meta.BG)
+	}
+}
+
+// TestArchiveQuery tests filtering entries by time, band and zoom.
+func TestArchiveQuery(t *testing.T) {
+	a := newTestArchive(t)
+
+	queryTests := []struct {
+		name  string
+		query ArchiveQuery
+		count int
+	}{
+		{"All", ArchiveQuery{}, 4},
+		{"Time range", ArchiveQuery{From: time.Date(2017, 4, 29, 1, 0, 0, 0, time.UTC), To: time.Date(2017, 4, 29, 2, 0, 0, 0, time.UTC)}, 2},
+		{"Band", ArchiveQuery{Bands: []Band{13}}, 4},
+		{"Other band", ArchiveQuery{Bands: []Band{3}}, 0},
+		{"Other zoom", ArchiveQuery{Zoom: 2}, 0},
+	}
+
+	for _, qt := range queryTests {
+		t.Run(qt.name, func(t *testing.T) {
+			entries, err := a.Query(qt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != qt.count {
+				t.Errorf("Expected %v entries, received %v", qt.count, len(entries))
+			}
+		})
+	}
+}
+
+// TestArchiveVerify tests that corrupt and missing images are reported.
+func TestArchiveVerify(t *testing.T) {
+	a := newTestArchive(t)
+
+	if bad, err := a.Verify(); err != nil || len(bad) != 0 {
+		t.Fatalf("Expected no bad images before corruption, received %v and %v", bad, err)
+	}
+
+	corrupt := SatTime{time.Date(2017, 4, 29, 1, 0, 0, 0, time.UTC)}
+	if err := os.WriteFile(a.Path(Band(13), Zoom(1), corrupt), []byte("not a png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(a.Path(Band(13), Zoom(1), SatTime{time.Date(2017, 4, 29, 3, 0, 0, 0, time.UTC)}))
+
+	bad, err := a.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 2 || !bad[0].Time.Equal(corrupt.Time) {
+		t.Errorf("Expected the corrupt and missing images, received %v", bad)
+	}
+}
+
+// TestArchivePrune tests that old images are removed from the index and
+// the disk.
+func TestArchivePrune(t *testing.T) {
+	a := newTestArchive(t)
+
+	pruned, err := a.Prune(time.Date(2017, 4, 29, 2, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 2 {
+		t.Errorf("Expected 2 entries pruned, received %v", len(pruned))
+	}
+
+	entries, _ := a.List()
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries left, received %v", len(entries))
+	}
+
+	if _, err := os.Stat(a.Path(Band(13), Zoom(1), SatTime{pruned[0].Time})); !os.IsNotExist(err) {
+		t.Errorf("Expected the pruned image to be deleted")
+	}
+}
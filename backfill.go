@@ -0,0 +1,208 @@
+package himago
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// archiveNoImage is the name of the file in the root of an Archive that
+// records frames which will never be available.
+const archiveNoImage = "noimage.jsonl"
+
+// noImageEntry is a line of the archive's noimage.jsonl.
+type noImageEntry struct {
+	Time time.Time `json:"time"`
+	Band Band      `json:"band"`
+	Zoom Zoom      `json:"zoom"`
+}
+
+// MarkNoImage records that there is permanently no image for band and
+// zoom at t, so that Backfill does not retry it.
+func (a *Archive) MarkNoImage(band Band, zoom Zoom, t SatTime) error {
+	line, err := json.Marshal(noImageEntry{t.UTC(), band, zoom})
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Join(a.Root, archiveNoImage), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// noImages returns the times marked by MarkNoImage for band and zoom.
+func (a *Archive) noImages(band Band, zoom Zoom) (map[time.Time]bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	times := map[time.Time]bool{}
+
+	f, err := os.Open(filepath.Join(a.Root, archiveNoImage))
+	if os.IsNotExist(err) {
+		return times, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var e noImageEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid no image record on line %v: %v", line, err)
+		}
+		if e.Band == band && e.Zoom == zoom {
+			times[e.Time.UTC()] = true
+		}
+	}
+
+	return times, scanner.Err()
+}
+
+// Frames returns the time of every image expected between from and to
+// inclusive, newest first, using the DefaultFetcher.
+func Frames(from, to SatTime) []SatTime {
+	return DefaultFetcher.Frames(from, to)
+}
+
+// Frames returns the time of every image expected between from and to
+// inclusive, newest first, stepping by the Provider's Cadence.
+func (f *Fetcher) Frames(from, to SatTime) []SatTime {
+	cadence := f.provider().Cadence()
+
+	from.RoundTo(cadence)
+	to.RoundTo(cadence)
+
+	var frames []SatTime
+	for t := to; !t.Before(from.Time); t.RollbackBy(cadence) {
+		frames = append(frames, t)
+	}
+
+	return frames
+}
+
+// BackfillOptions configures Backfill.
+type BackfillOptions struct {
+	Band Band
+	Zoom Zoom
+
+	// From and To bound the frames to fill, inclusive.
+	From SatTime
+	To   SatTime
+
+	// BG and FG are the Background and colour images are drawn with, see
+	// StitchBackground.
+	BG Background
+	FG Color
+
+	// NoImageAfter is how old a frame must be before a "No Image" is
+	// taken to be permanent and recorded. Newer frames may not have been
+	// published yet. Defaults to one hour.
+	NoImageAfter time.Duration
+}
+
+// BackfillResult summarises a Backfill.
+type BackfillResult struct {
+	// Expected is the number of frames between From and To.
+	Expected int
+
+	// Present frames were already in the archive or recorded as "No Image".
+	Present int
+
+	Downloaded int
+
+	// NoImage frames were unavailable. Those older than NoImageAfter
+	// have been recorded and will not be tried again.
+	NoImage int
+
+	// Failed frames could not be downloaded and will be retried by the
+	// next Backfill.
+	Failed int
+}
+
+// Backfill downloads every frame between opts.From and opts.To that is
+// missing from the archive. The archive index records progress, so a
+// Backfill that is interrupted resumes where it left off when run again.
+// Requests are limited by the Fetcher's RateLimit.
+//
+// A frame that fails to download does not stop the Backfill. The last
+// error is returned along with the result once every frame has been tried.
+func (f *Fetcher) Backfill(a *Archive, opts BackfillOptions) (BackfillResult, error) {
+	var result BackfillResult
+
+	if opts.NoImageAfter == 0 {
+		opts.NoImageAfter = time.Hour
+	}
+
+	entries, err := a.Query(ArchiveQuery{Bands: []Band{opts.Band}, Zoom: opts.Zoom})
+	if err != nil {
+		return result, err
+	}
+	present := map[time.Time]bool{}
+	for _, e := range entries {
+		present[e.Time.UTC()] = true
+	}
+
+	noImages, err := a.noImages(opts.Band, opts.Zoom)
+	if err != nil {
+		return result, err
+	}
+
+	log := f.logger().With("band", int(opts.Band), "zoom", int(opts.Zoom))
+
+	var lastErr error
+	frames := f.Frames(opts.From, opts.To)
+	result.Expected = len(frames)
+
+	for _, t := range frames {
+		if present[t.UTC()] || noImages[t.UTC()] {
+			result.Present++
+			continue
+		}
+
+		capture, err := f.GetExactCapture(opts.Band, opts.Zoom, t)
+		switch {
+		case err == ErrNoImage:
+			result.NoImage++
+			if time.Since(t.Time) < opts.NoImageAfter {
+				log.Info("no image yet", "time", t.Time)
+				continue
+			}
+
+			log.Info("no image, recording as permanent", "time", t.Time)
+			if err := a.MarkNoImage(opts.Band, opts.Zoom, t); err != nil {
+				return result, err
+			}
+		case err != nil:
+			log.Warn("backfill failed", "time", t.Time, "err", err)
+			result.Failed++
+			lastErr = err
+		default:
+			if _, err := a.Put(capture, opts.BG, opts.FG); err != nil {
+				return result, err
+			}
+			result.Downloaded++
+		}
+	}
+
+	if lastErr != nil {
+		return result, fmt.Errorf("%v of %v frames failed, last error: %v", result.Failed, result.Expected, lastErr)
+	}
+
+	return result, nil
+}
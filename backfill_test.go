@@ -0,0 +1,158 @@
+package himago
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFrames tests that frames are listed newest first, rounded to the
+// 10 minute cadence.
+func TestFrames(t *testing.T) {
+	framesTests := []struct {
+		name     string
+		from, to time.Time
+		count    int
+		first    string
+	}{
+		{"One hour", time.Date(2017, 4, 29, 0, 0, 0, 0, time.UTC), time.Date(2017, 4, 29, 1, 0, 0, 0, time.UTC), 7, "01:00"},
+		{"Rounded", time.Date(2017, 4, 29, 0, 5, 0, 0, time.UTC), time.Date(2017, 4, 29, 0, 39, 0, 0, time.UTC), 4, "00:30"},
+		{"Single", time.Date(2017, 4, 29, 0, 10, 0, 0, time.UTC), time.Date(2017, 4, 29, 0, 10, 0, 0, time.UTC), 1, "00:10"},
+		{"Reversed", time.Date(2017, 4, 29, 1, 0, 0, 0, time.UTC), time.Date(2017, 4, 29, 0, 0, 0, 0, time.UTC), 0, ""},
+	}
+
+	for _, ft := range framesTests {
+		t.Run(ft.name, func(t *testing.T) {
+			frames := Frames(SatTime{ft.from}, SatTime{ft.to})
+			if len(frames) != ft.count {
+				t.Fatalf("Expected %v frames, received %v", ft.count, len(frames))
+			}
+			if len(frames) > 0 && frames[0].Format("15:04") != ft.first {
+				t.Errorf("Expected the first frame at %v, received %v", ft.first, frames[0].Format("15:04"))
+			}
+		})
+	}
+}
+
+// TestFetcherFrames tests that frames step by the Provider's Cadence.
+func TestFetcherFrames(t *testing.T) {
+	f := &Fetcher{Provider: fakeProvider{}}
+
+	frames := f.Frames(SatTime{time.Date(2017, 4, 29, 0, 2, 0, 0, time.UTC)}, SatTime{time.Date(2017, 4, 29, 0, 33, 0, 0, time.UTC)})
+	if len(frames) != 7 {
+		t.Fatalf("Expected 7 frames, received %v", len(frames))
+	}
+
+	if first := frames[0].Format("15:04"); first != "00:30" {
+		t.Errorf("Expected the first frame at 00:30, received %v", first)
+	}
+}
+
+// TestFramesZone tests that frames keep the clock of SatTimes in other
+// zones, as SatTime.Round does.
+func TestFramesZone(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+
+	frames := Frames(SatTime{time.Date(2017, 4, 29, 9, 0, 0, 0, jst)}, SatTime{time.Date(2017, 4, 29, 9, 15, 0, 0, jst)})
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, received %v", len(frames))
+	}
+
+	if expected := time.Date(2017, 4, 29, 9, 10, 0, 0, time.UTC); !frames[0].Equal(expected) {
+		t.Errorf("Expected %v, received %v", expected, frames[0].Time)
+	}
+}
+
+// newBackfillServer serves blank tiles, except "No Image" at 00:20 and
+// errors at 00:30 until fail is cleared.
+func newBackfillServer(t *testing.T) (*Fetcher, func(bool)) {
+	tile := blankTile(t, defaultTileSize)
+	noImage := noImagePNG(t)
+
+	var mu sync.Mutex
+	fail := true
+
+	_, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case strings.Contains(r.URL.Path, "/002000_"):
+			w.Write(noImage)
+		case strings.Contains(r.URL.Path, "/003000_") && fail:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		default:
+			w.Write(tile)
+		}
+	})
+
+	return f, func(v bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		fail = v
+	}
+}
+
+// TestBackfill tests that Backfill downloads missing frames, records
+// "No Image" frames and only retries failures when run again.
+func TestBackfill(t *testing.T) {
+	a, err := OpenArchive(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, setFail := newBackfillServer(t)
+
+	opts := BackfillOptions{
+		Band: Band(13),
+		Zoom: Zoom(1),
+		From: SatTime{time.Date(2017, 4, 29, 0, 0, 0, 0, time.UTC)},
+		To:   SatTime{time.Date(2017, 4, 29, 0, 50, 0, 0, time.UTC)},
+	}
+
+	if _, err := f.DownloadToArchive(a, opts.Band, opts.Zoom, SatTime{time.Date(2017, 4, 29, 0, 10, 0, 0, time.UTC)}, Color{}, Color{}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := f.Backfill(a, opts)
+	if err == nil {
+		t.Error("Expected an error for the failed frame")
+	}
+	if expected := (BackfillResult{Expected: 6, Present: 1, Downloaded: 3, NoImage: 1, Failed: 1}); result != expected {
+		t.Errorf("Expected %+v from the first run, received %+v", expected, result)
+	}
+
+	// The rerun only fetches the frame that failed
+	setFail(false)
+	result, err = f.Backfill(a, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (BackfillResult{Expected: 6, Present: 5, Downloaded: 1}); result != expected {
+		t.Errorf("Expected %+v from the second run, received %+v", expected, result)
+	}
+
+	entries, _ := a.List()
+	if len(entries) != 5 {
+		t.Errorf("Expected 5 archive entries, received %v", len(entries))
+	}
+}
+
+// TestRateLimit tests that requests are spaced by the RateLimit.
+func TestRateLimit(t *testing.T) {
+	f := newTileServer(t)
+
+	f.RateLimit = 50 * time.Millisecond
+
+	start := time.Now()
+	if _, err := f.GetTiles(Band(0), Zoom(2), SatTime{time.Date(2017, 4, 29, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Four requests need at least three intervals
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 4 requests to take at least 150ms, received %v", elapsed)
+	}
+}
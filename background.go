@@ -0,0 +1,206 @@
+package himago
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Gradient returns a ColorMap with the colours spaced evenly from 0 to 1,
+// for use as the Stops of a LinearGradient or RadialGradient.
+func Gradient(colors ...Color) ColorMap {
+	m := ColorMap{Name: "gradient"}
+
+	for i, c := range colors {
+		position := 0.0
+		if len(colors) > 1 {
+			position = float64(i) / float64(len(colors)-1)
+		}
+		m.Stops = append(m.Stops, ColorStop{position, c.NRGBA})
+	}
+
+	return m
+}
+
+// Background fills the area behind the Tiles of a band image, see
+// StitchBackground. Color is a Background of a single colour.
+type Background interface {
+	// Render returns the background for an image with the given bounds.
+	Render(bounds image.Rectangle) image.Image
+}
+
+// Render returns a uniform image of the Color.
+func (c Color) Render(bounds image.Rectangle) image.Image {
+	return image.NewUniform(c)
+}
+
+// orBlack returns bg, or opaque black if bg is nil.
+func orBlack(bg Background) Background {
+	if bg == nil {
+		return Color{color.NRGBA{0, 0, 0, 255}}
+	}
+
+	return bg
+}
+
+// backgroundName describes bg for Metadata: the value of a Color, or the
+// kind of any other Background. A nil Background is the opaque black it
+// is drawn as.
+func backgroundName(bg Background) string {
+	switch b := orBlack(bg).(type) {
+	case Color:
+		return b.String()
+	case LinearGradient:
+		return "linear-gradient"
+	case RadialGradient:
+		return "radial-gradient"
+	case ImageBackground:
+		return "image"
+	}
+
+	return fmt.Sprintf("%T", bg)
+}
+
+// LinearGradient is a Background which changes colour along a straight
+// line.
+type LinearGradient struct {
+	Stops ColorMap
+
+	// Angle is the direction of the gradient in degrees clockwise from
+	// left to right, so 90 runs from top to bottom. As in CSS, the first
+	// and last stops meet the corners of the image.
+	Angle float64
+}
+
+// Render returns the gradient drawn across bounds.
+func (g LinearGradient) Render(bounds image.Rectangle) image.Image {
+	angle := g.Angle / degreesPerRadian
+	dx, dy := math.Cos(angle), math.Sin(angle)
+
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	halfLength := math.Abs(w/2*dx) + math.Abs(h/2*dy)
+
+	return &gradientImage{bounds, func(x, y float64) color.NRGBA {
+		if halfLength == 0 {
+			return g.Stops.At(0)
+		}
+		return g.Stops.At(((x*dx+y*dy)/halfLength + 1) / 2)
+	}}
+}
+
+// RadialGradient is a Background which changes colour outwards from the
+// centre of the image.
+type RadialGradient struct {
+	Stops ColorMap
+
+	// Radius at which the last stop is reached, as a fraction of half the
+	// width of the image. 0 reaches it at the corners.
+	Radius float64
+}
+
+// Render returns the gradient centred in bounds.
+func (g RadialGradient) Render(bounds image.Rectangle) image.Image {
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+
+	radius := g.Radius * w / 2
+	if radius <= 0 {
+		radius = math.Hypot(w, h) / 2
+	}
+
+	return &gradientImage{bounds, func(x, y float64) color.NRGBA {
+		if radius == 0 {
+			return g.Stops.At(0)
+		}
+		return g.Stops.At(math.Hypot(x, y) / radius)
+	}}
+}
+
+// gradientImage is an image whose colour is a function of the position
+// relative to its centre.
+type gradientImage struct {
+	rect image.Rectangle
+	at   func(x, y float64) color.NRGBA
+}
+
+func (g *gradientImage) ColorModel() color.Model { return color.NRGBAModel }
+func (g *gradientImage) Bounds() image.Rectangle { return g.rect }
+
+func (g *gradientImage) At(x, y int) color.Color {
+	return g.at(
+		float64(x-g.rect.Min.X)+0.5-float64(g.rect.Dx())/2,
+		float64(y-g.rect.Min.Y)+0.5-float64(g.rect.Dy())/2,
+	)
+}
+
+// ImageBackground is a Background of a picture, such as a starfield,
+// scaled to cover the image with ScaleImage.
+type ImageBackground struct {
+	Image image.Image
+}
+
+// Render returns the picture scaled to bounds.
+func (b ImageBackground) Render(bounds image.Rectangle) image.Image {
+	return ScaleImage(b.Image, bounds)
+}
+
+// ScaleImage scales img to fill size, keeping its aspect ratio and cropping
+// whatever overflows, so that a photo such as a starfield can be used as a
+// background. Pixels are interpolated bilinearly.
+func ScaleImage(img image.Image, size image.Rectangle) *image.NRGBA {
+	out := image.NewNRGBA(size)
+
+	src := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	sw, sh := float64(src.Rect.Dx()), float64(src.Rect.Dy())
+	if sw == 0 || sh == 0 {
+		return out
+	}
+
+	scale := math.Max(float64(size.Dx())/sw, float64(size.Dy())/sh)
+
+	// Offsets centre the cropped image
+	offsetX := (sw - float64(size.Dx())/scale) / 2
+	offsetY := (sh - float64(size.Dy())/scale) / 2
+
+	for y := size.Min.Y; y < size.Max.Y; y++ {
+		for x := size.Min.X; x < size.Max.X; x++ {
+			sx := offsetX + (float64(x-size.Min.X)+0.5)/scale - 0.5
+			sy := offsetY + (float64(y-size.Min.Y)+0.5)/scale - 0.5
+			out.SetNRGBA(x, y, bilinear(src, sx, sy))
+		}
+	}
+
+	return out
+}
+
+// bilinear samples src at a fractional position, clamping to its edges.
+func bilinear(src *image.NRGBA, x, y float64) color.NRGBA {
+	maxX, maxY := src.Rect.Dx()-1, src.Rect.Dy()-1
+
+	x = math.Max(0, math.Min(float64(maxX), x))
+	y = math.Max(0, math.Min(float64(maxY), y))
+
+	x0, y0 := int(x), int(y)
+	x1, y1 := x0+1, y0+1
+	if x1 > maxX {
+		x1 = maxX
+	}
+	if y1 > maxY {
+		y1 = maxY
+	}
+	tx, ty := x-float64(x0), y-float64(y0)
+
+	a, b := src.NRGBAAt(x0, y0), src.NRGBAAt(x1, y0)
+	c, d := src.NRGBAAt(x0, y1), src.NRGBAAt(x1, y1)
+
+	mix := func(a, b, c, d uint8) uint8 {
+		top := float64(a) + (float64(b)-float64(a))*tx
+		bottom := float64(c) + (float64(d)-float64(c))*tx
+		return uint8(math.Round(top + (bottom-top)*ty))
+	}
+
+	return color.NRGBA{mix(a.R, b.R, c.R, d.R), mix(a.G, b.G, c.G, d.G), mix(a.B, b.B, c.B, d.B), mix(a.A, b.A, c.A, d.A)}
+}
@@ -0,0 +1,120 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestBackgrounds tests the colour each kind of Background renders at
+// points across its bounds.
+func TestBackgrounds(t *testing.T) {
+	black := Color{color.NRGBA{0, 0, 0, 255}}
+	white := Color{color.NRGBA{255, 255, 255, 255}}
+	red := Color{color.NRGBA{255, 0, 0, 255}}
+
+	// A 2x2 picture scaled up to cover the bounds
+	picture := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	picture.SetNRGBA(0, 0, white.NRGBA)
+	picture.SetNRGBA(1, 1, red.NRGBA)
+
+	bounds := image.Rect(0, 0, 100, 100)
+
+	backgroundTests := []struct {
+		name       string
+		background Background
+		x, y       int
+		out        color.NRGBA
+	}{
+		{"Colour", red, 50, 50, red.NRGBA},
+		{"Linear start", LinearGradient{Stops: Gradient(black, white)}, 0, 50, color.NRGBA{1, 1, 1, 255}},
+		{"Linear middle", LinearGradient{Stops: Gradient(black, red, white)}, 50, 10, color.NRGBA{255, 3, 3, 255}},
+		{"Linear vertical", LinearGradient{Stops: Gradient(black, white), Angle: 90}, 10, 99, color.NRGBA{254, 254, 254, 255}},
+		{"Radial centre", RadialGradient{Stops: Gradient(white, black)}, 50, 50, color.NRGBA{254, 254, 254, 255}},
+		{"Radial beyond radius", RadialGradient{Stops: Gradient(white, black), Radius: 0.5}, 99, 50, black.NRGBA},
+		{"Image corner", ImageBackground{picture}, 0, 0, white.NRGBA},
+		{"Image opposite corner", ImageBackground{picture}, 99, 99, red.NRGBA},
+	}
+
+	for _, bt := range backgroundTests {
+		t.Run(bt.name, func(t *testing.T) {
+			img := bt.background.Render(bounds)
+			c := color.NRGBAModel.Convert(img.At(bt.x, bt.y)).(color.NRGBA)
+			if channelDiff(c.R, bt.out.R) > 3 || channelDiff(c.G, bt.out.G) > 3 || channelDiff(c.B, bt.out.B) > 3 || c.A != bt.out.A {
+				t.Errorf("Expected %v at %v,%v, received %v", bt.out, bt.x, bt.y, c)
+			}
+		})
+	}
+}
+
+// TestScaleImageAspect tests that ScaleImage keeps the aspect ratio of a
+// picture, cropping it to fill the output.
+func TestScaleImageAspect(t *testing.T) {
+	// A wide picture is cropped at the sides rather than squashed
+	picture := image.NewNRGBA(image.Rect(0, 0, 40, 10))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 10; y++ {
+			if x >= 15 && x < 25 {
+				picture.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+			}
+		}
+	}
+
+	out := ScaleImage(picture, image.Rect(0, 0, 20, 20))
+	if c := out.NRGBAAt(10, 10); c.R != 255 {
+		t.Errorf("Expected white at the centre, received %v", c)
+	}
+	// Only the middle quarter of the picture is white and it fills the output
+	if c := out.NRGBAAt(1, 10); c.R != 255 {
+		t.Errorf("Expected white at the edge with the sides cropped, received %v", c)
+	}
+}
+
+// TestStitchBackground tests that band tiles are drawn over a gradient.
+func TestStitchBackground(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, defaultTileSize, defaultTileSize))
+	img.SetNRGBA(0, 0, color.NRGBA{255, 255, 255, 255})
+
+	fg := Color{color.NRGBA{0, 255, 0, 255}}
+	bg := LinearGradient{Stops: Gradient(Color{color.NRGBA{0, 0, 0, 255}}, Color{color.NRGBA{0, 0, 255, 255}})}
+
+	out := StitchBackground(Band(13), [][]Tile{{{img}}}, bg, fg)
+
+	if c := out.RGBAAt(0, 0); c != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("Expected the foreground on the tile, received %v", c)
+	}
+	if c := out.RGBAAt(defaultTileSize-1, 10); c.B < 250 {
+		t.Errorf("Expected the end of the gradient behind the tile, received %v", c)
+	}
+}
+
+// TestBackgroundMetadata tests that the Background is recorded in the
+// Metadata of a drawn image.
+func TestBackgroundMetadata(t *testing.T) {
+	capture := &Capture{Band: Band(13), Zoom: Zoom(1)}
+	white := Color{color.NRGBA{255, 255, 255, 255}}
+
+	if meta := NewMetadata(capture, white, white); meta.BG != "#ffffff" {
+		t.Errorf("Expected \"#ffffff\", received %q", meta.BG)
+	}
+
+	if meta := NewMetadata(capture, RadialGradient{Stops: Gradient(white, white)}, white); meta.BG != "radial-gradient" {
+		t.Errorf("Expected \"radial-gradient\", received %q", meta.BG)
+	}
+}
+
+// TestBackgroundNil tests that a nil Background is drawn, and recorded,
+// as opaque black.
+func TestBackgroundNil(t *testing.T) {
+	tiles := [][]Tile{{{image.NewRGBA(image.Rect(0, 0, 4, 4))}}}
+	white := Color{color.NRGBA{255, 255, 255, 255}}
+
+	out := StitchBackground(Band(13), tiles, nil, white)
+	if c := out.RGBAAt(0, 0); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected opaque black, received %v", c)
+	}
+
+	if meta := NewMetadata(&Capture{Band: Band(13), Zoom: Zoom(1)}, nil, white); meta.BG != "#000000" {
+		t.Errorf("Expected \"#000000\", received %q", meta.BG)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // Band is an int representing the electromagnetic frequency that an image was
@@ -29,12 +30,13 @@ import (
 //
 // 0 represents the default band which is a full-colour version
 // combining the visible light bands (RGB)
+//
+// Bands and Band.Info provide the same information programmatically.
 type Band int
 
 var (
-	uRLPrefix  = "http://himawari8-dl.nict.go.jp/himawari8/img/"
-	uRLSuffix  = "/%vd/550/%02d/%02d/%02d/%02d%02d00_%v_%v.png"
-	defaultURL = uRLPrefix + "D531106" + uRLSuffix
+	uRLPrefix = "http://himawari8-dl.nict.go.jp/himawari8/img/"
+	uRLSuffix = "/%vd/550/%02d/%02d/%02d/%02d%02d00_%v_%v.png"
 )
 
 // String returns the Band int as a string. Nothing to see here.
@@ -46,26 +48,28 @@ func (b *Band) String() string {
 // The URL will be in Sprintf format e.g. a band
 // of "3" will set the value to be:
 //     "http://himawari8-dl.nict.go.jp/himawari8/img/FULL_24H/B03/%vd/550/%02d/%02d/%02d/%02d%02d00_%v_%v.png"
+//
+// The URL is always that of the Himawari8 Provider.
 func (b *Band) URL() string {
-
-	if int(*b) == 0 {
-		return defaultURL
-	}
-
-	// Construct the full URL and return it
-	return fmt.Sprintf("%sFULL_24h/B%02d%s", uRLPrefix, *b, uRLSuffix)
-
+	return Himawari8.bandURL(*b)
 }
 
 // Set will take the flag passed as a string and attempt to convert it
 // to an int. That int is used the set the value of the Band.
+// Names from BandInfo.Aliases such as "B13", "red" or "ir-10.4" are also
+// accepted, ignoring case.
 func (b *Band) Set(flag string) error {
+	if named, ok := bandNames[strings.ToLower(flag)]; ok {
+		*b = named
+		return nil
+	}
+
 	// Attempt to cast to int
 	band, err := strconv.Atoi(flag)
 
 	// If it's not an integer or isn't between 1 and 16 (inclusive) error
 	if err != nil || band < 1 || band > 16 {
-		return errors.New("Band must be an integer between 1 and 16 inclusive")
+		return errors.New("Band must be an integer between 1 and 16 inclusive or a name such as B13, red or ir-10.4")
 	}
 
 	// Set the value of the band
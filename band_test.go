@@ -1,6 +1,10 @@
 package himago
 
-import "testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
 
 // TestBandString tests band.String() returns the band as a string.
 // The flag.Value interface requires a String() method.
@@ -63,6 +67,13 @@ func TestBandSet(t *testing.T) {
 		{"Band 01", "01", 1},
 		{"Band 001", "001", 1},
 		{"Band 16", "16", 16},
+		{"Band B13", "B13", 13},
+		{"Band b03", "b03", 3},
+		{"Band red", "red", 3},
+		{"Band Blue", "Blue", 1},
+		{"Band ir-10.4", "ir-10.4", 13},
+		{"Band nir-1.6", "nir-1.6", 5},
+		{"Band clean-longwave-window", "clean-longwave-window", 13},
 	}
 
 	for _, vb := range validBands {
@@ -96,6 +107,9 @@ func TestBandSetInvalid(t *testing.T) {
 		{"Band 5 with leading whitespace", " 5"},
 		{"Band 5 with trailing whitespace", "5 "},
 		{"Band whitespace", " "},
+		{"Band B0", "B0"},
+		{"Band B17", "B17"},
+		{"Band ir-0.64", "ir-0.64"},
 	}
 
 	for _, ib := range invalidBands {
@@ -111,3 +125,55 @@ func TestBandSetInvalid(t *testing.T) {
 		})
 	}
 }
+
+// TestBandInfo tests that every band has an entry in the registry
+// with a wavelength matching the Band documentation.
+func TestBandInfo(t *testing.T) {
+	bands := Bands()
+	if len(bands) != 16 {
+		t.Fatalf("Expected 16 bands, received %v", len(bands))
+	}
+
+	for i, info := range bands {
+		if info.Band != Band(i+1) {
+			t.Errorf("Expected band %v, received %v", i+1, info.Band)
+		}
+	}
+
+	info, ok := Band(13).Info()
+	if !ok || info.Wavelength != 10.4 || info.Category != "Far-IR" {
+		t.Errorf("Unexpected info for band 13: %+v", info)
+	}
+
+	if _, ok := Band(17).Info(); ok {
+		t.Errorf("Expected no info for band 17")
+	}
+}
+
+// TestBandsCopy tests that modifying the slice returned by Bands does not
+// change the registry.
+func TestBandsCopy(t *testing.T) {
+	Bands()[12].Name = "changed"
+
+	if info, _ := Band(13).Info(); info.Name != "Clean longwave window" {
+		t.Errorf("Expected \"Clean longwave window\", received %q", info.Name)
+	}
+}
+
+// TestWriteBands tests that the band listing has a header and one line
+// per band.
+func TestWriteBands(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBands(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 17 {
+		t.Fatalf("Expected 17 lines, received %v", len(lines))
+	}
+
+	if !strings.HasPrefix(lines[0], "BAND") || !strings.Contains(lines[13], "Clean longwave window") {
+		t.Errorf("Unexpected listing:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,121 @@
+package himago
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BandInfo describes one of the Himawari 8 bands.
+type BandInfo struct {
+	Band Band
+
+	// Name is a short human-readable name such as "Red" or
+	// "Clean longwave window".
+	Name string
+
+	// Wavelength is the centre wavelength in µm.
+	Wavelength float64
+
+	// Category is one of "Visible", "Near-IR", "Short-IR", "Mid-IR" or
+	// "Far-IR", as in the Band documentation.
+	Category string
+
+	// Resolution is the size of a pixel at the sub-satellite point in km.
+	// The tiles served by NICT are resampled so this is only indicative.
+	Resolution float64
+
+	// ColorMap is the recommended colour map for rendering the band.
+	ColorMap ColorMap
+}
+
+// bandInfos is indexed by Band. Band 0 is the full-colour image.
+var bandInfos = []BandInfo{
+	{0, "True colour", 0, "Visible", 1, Greyscale},
+	{1, "Blue", 0.47, "Visible", 1, Greyscale},
+	{2, "Green", 0.51, "Visible", 1, Greyscale},
+	{3, "Red", 0.64, "Visible", 0.5, Greyscale},
+	{4, "Vegetation", 0.86, "Near-IR", 1, Greyscale},
+	{5, "Snow and ice", 1.6, "Near-IR", 2, Greyscale},
+	{6, "Cloud particle size", 2.3, "Near-IR", 2, Greyscale},
+	{7, "Shortwave window", 3.9, "Short-IR", 2, EnhancedIR},
+	{8, "Upper-level water vapour", 6.2, "Mid-IR", 2, WaterVapour},
+	{9, "Mid-level water vapour", 6.9, "Mid-IR", 2, WaterVapour},
+	{10, "Lower-level water vapour", 7.3, "Mid-IR", 2, WaterVapour},
+	{11, "Cloud-top phase", 8.6, "Far-IR", 2, EnhancedIR},
+	{12, "Ozone", 9.6, "Far-IR", 2, EnhancedIR},
+	{13, "Clean longwave window", 10.4, "Far-IR", 2, EnhancedIR},
+	{14, "Longwave window", 11.2, "Far-IR", 2, EnhancedIR},
+	{15, "Dirty longwave window", 12.4, "Far-IR", 2, EnhancedIR},
+	{16, "CO2", 13.3, "Far-IR", 2, EnhancedIR},
+}
+
+// bandNames maps every lower case name accepted by Band.Set to its Band.
+var bandNames = map[string]Band{}
+
+func init() {
+	for _, info := range Bands() {
+		for _, name := range info.Aliases() {
+			bandNames[name] = info.Band
+		}
+	}
+}
+
+// Bands returns information about bands 1 to 16. The slice is a copy so
+// callers may modify it freely.
+func Bands() []BandInfo {
+	return append([]BandInfo(nil), bandInfos[1:]...)
+}
+
+// WriteBands writes a table of every band to w, one BandInfo per line
+// under a header.
+func WriteBands(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%-5s %8s  %-8s  %-6s  %-12s  %s\n",
+		"BAND", "WAVE", "CATEGORY", "RES", "COLOR MAP", "NAME"); err != nil {
+		return err
+	}
+
+	for _, info := range Bands() {
+		if _, err := fmt.Fprintln(w, info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Info returns information about the Band. The second return value is
+// false if the Band is not between 0 and 16.
+func (b Band) Info() (BandInfo, bool) {
+	if b < 0 || int(b) >= len(bandInfos) {
+		return BandInfo{}, false
+	}
+
+	return bandInfos[b], true
+}
+
+// Aliases returns the names Band.Set accepts for the band, e.g. for band 3
+// "b3", "b03", "red" and "vis-0.64".
+func (i BandInfo) Aliases() []string {
+	prefix := "ir"
+	switch i.Category {
+	case "Visible":
+		prefix = "vis"
+	case "Near-IR":
+		prefix = "nir"
+	}
+
+	return []string{
+		fmt.Sprintf("b%d", i.Band),
+		fmt.Sprintf("b%02d", i.Band),
+		strings.Replace(strings.ToLower(i.Name), " ", "-", -1),
+		fmt.Sprintf("%s-%g", prefix, i.Wavelength),
+	}
+}
+
+// String formats the BandInfo as a row of the table in the Band
+// documentation.
+func (i BandInfo) String() string {
+	return fmt.Sprintf("%-5d %6.2fµm  %-8s  %-4gkm  %-12s  %s",
+		i.Band, i.Wavelength, i.Category, i.Resolution, i.ColorMap.Name, i.Name)
+}
@@ -0,0 +1,115 @@
+package himago
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache stores downloaded responses on disk alongside the HTTP validators
+// (ETag and Last-Modified) needed to revalidate them with a conditional
+// request. Each URL is stored as two files named after its SHA-1 hash: the
+// raw body and a small JSON file holding the validators.
+type Cache struct {
+	Dir string
+}
+
+// cacheEntry is a single cached response.
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Expires      time.Time `json:"expires,omitempty"`
+
+	body []byte
+}
+
+// NewCache returns a Cache rooted at dir, creating the directory if it does
+// not already exist.
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Cache{Dir: dir}, nil
+}
+
+// fresh returns true if the entry can be used without revalidating it.
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return now.Before(e.Expires)
+}
+
+// path returns the location of a cache file for url with the given extension.
+func (c *Cache) path(url, ext string) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("%x%s", sha1.Sum([]byte(url)), ext))
+}
+
+// get returns the cached entry for url. A missing entry is not an error,
+// both return values will be nil.
+func (c *Cache) get(url string) (*cacheEntry, error) {
+	meta, err := os.ReadFile(c.path(url, ".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e cacheEntry
+	if err := json.Unmarshal(meta, &e); err != nil {
+		return nil, err
+	}
+
+	e.body, err = os.ReadFile(c.path(url, ".body"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// put writes the entry to disk. The body is written before the validators
+// so a partially written entry is never mistaken for a complete one.
+func (c *Cache) put(e *cacheEntry) error {
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.path(e.URL, ".body"), e.body, 0644); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path(e.URL, ".json"), meta, 0644)
+}
+
+// maxAge parses the max-age directive of a Cache-Control header.
+// Responses marked no-cache or no-store, or without a max-age,
+// return zero and must always be revalidated.
+func maxAge(cacheControl string) time.Duration {
+	var age time.Duration
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			return 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err == nil && seconds > 0 {
+				age = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return age
+}
@@ -0,0 +1,93 @@
+package himago
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestMaxAge tests parsing of the Cache-Control header.
+func TestMaxAge(t *testing.T) {
+	maxAgeTests := []struct {
+		in  string
+		out time.Duration
+	}{
+		{"", 0},
+		{"max-age=60", time.Minute},
+		{"public, max-age=600", 10 * time.Minute},
+		{"max-age=60, no-cache", 0},
+		{"no-store", 0},
+		{"max-age=-1", 0},
+		{"max-age=abc", 0},
+	}
+
+	for _, mt := range maxAgeTests {
+		t.Run(mt.in, func(t *testing.T) {
+			if age := maxAge(mt.in); age != mt.out {
+				t.Errorf("Expected %v, received %v", mt.out, age)
+			}
+		})
+	}
+}
+
+// TestFetcherRevalidates tests that a cached response is revalidated with
+// If-None-Match and that a 304 is served from the cache.
+func TestFetcherRevalidates(t *testing.T) {
+	requests := 0
+	server, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("tile"))
+	})
+
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Cache = cache
+
+	for i := 0; i < 2; i++ {
+		body, err := f.get(f.logger(), server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "tile" {
+			t.Errorf("Expected \"tile\", received %q", body)
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, received %v", requests)
+	}
+}
+
+// TestFetcherFresh tests that a response with a max-age is served from
+// the cache without contacting the server.
+func TestFetcherFresh(t *testing.T) {
+	requests := 0
+	server, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"date":"2017-04-29 15:40:00"}`))
+	})
+
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Cache = cache
+
+	for i := 0; i < 2; i++ {
+		if _, err := f.get(f.logger(), server.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected 1 request, received %v", requests)
+	}
+}
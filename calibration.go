@@ -0,0 +1,133 @@
+package himago
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// zeroCelsius is 0°C in Kelvin.
+const zeroCelsius = 273.15
+
+// Kelvin converts a temperature in °C to Kelvin.
+func Kelvin(celsius float64) float64 {
+	return celsius + zeroCelsius
+}
+
+// Celsius converts a temperature in Kelvin to °C.
+func Celsius(kelvin float64) float64 {
+	return kelvin - zeroCelsius
+}
+
+// Calibration maps the 8-bit pixel values of an infrared band to
+// approximate brightness temperatures. The tiles are linearly scaled with
+// the coldest temperatures brightest, so a value of 0 is Warm and 255 is
+// Cold.
+//
+// The Calibrations returned by Band.Calibration are estimates, not values
+// taken from JMA calibration tables, and may be out by ten Kelvin or more.
+// Use them to find relatively cold or warm regions, not to measure
+// temperature.
+type Calibration struct {
+	Warm float64
+	Cold float64
+}
+
+// calibrations are estimated display ranges, in Kelvin, of the infrared
+// bands. They were chosen by eye to span typical cloud-top and surface
+// temperatures and are not derived from any published calibration table.
+var calibrations = map[Band]Calibration{
+	7:  {350, 200},
+	8:  {260, 190},
+	9:  {270, 190},
+	10: {280, 190},
+	11: {320, 180},
+	12: {300, 180},
+	13: {320, 180},
+	14: {320, 180},
+	15: {320, 180},
+	16: {290, 180},
+}
+
+// Calibration returns the estimated Calibration for the Band. The second
+// return value is false for bands 0 to 6 which measure reflected light
+// rather than temperature.
+func (b Band) Calibration() (Calibration, bool) {
+	c, ok := calibrations[b]
+	return c, ok
+}
+
+// Kelvin returns the temperature of a pixel value.
+func (c Calibration) Kelvin(v uint8) float64 {
+	return c.Warm + (c.Cold-c.Warm)*float64(v)/255
+}
+
+// Value returns the pixel value closest to a temperature in Kelvin.
+// Temperatures outside the calibrated range are clamped.
+func (c Calibration) Value(kelvin float64) uint8 {
+	v := (kelvin - c.Warm) / (c.Cold - c.Warm) * 255
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// pixelValue returns the intensity of the pixel composited over black,
+// which is how a band appears when stitched with a white foreground and
+// black background.
+func pixelValue(img image.Image, x, y int) uint8 {
+	return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+}
+
+// Temperature returns the approximate brightness temperature in Kelvin of
+// the pixel at x, y of an image of band. img should be drawn with a white
+// foreground on a black background so that intensity is preserved.
+func Temperature(band Band, img image.Image, x, y int) (float64, error) {
+	c, ok := band.Calibration()
+	if !ok {
+		return 0, fmt.Errorf("band %v is not an infrared band", band)
+	}
+
+	if !(image.Point{x, y}.In(img.Bounds())) {
+		return 0, fmt.Errorf("pixel %v,%v is outside the image", x, y)
+	}
+
+	return c.Kelvin(pixelValue(img, x, y)), nil
+}
+
+// TemperatureAt returns the approximate brightness temperature in Kelvin
+// at a latitude and longitude on a full-disk image of band from provider.
+// A nil provider is the DefaultFetcher's Provider.
+func TemperatureAt(provider Provider, band Band, img image.Image, lat, lon float64) (float64, error) {
+	bounds := img.Bounds()
+
+	x, y, ok := ProviderProjection(provider, bounds.Dx()).ToPixel(lat, lon)
+	if !ok {
+		return 0, fmt.Errorf("%v,%v is not visible from the satellite", lat, lon)
+	}
+
+	return Temperature(band, img, bounds.Min.X+int(x), bounds.Min.Y+int(y))
+}
+
+// TemperatureMask returns an image the size of img where every pixel colder
+// than the threshold, in Kelvin, is set to c and every other pixel is
+// transparent. For example cloud tops colder than -60°C:
+//
+//	mask, err := TemperatureMask(Band(13), img, Kelvin(-60), red)
+func TemperatureMask(band Band, img image.Image, threshold float64, c color.Color) (*image.NRGBA, error) {
+	cal, ok := band.Calibration()
+	if !ok {
+		return nil, fmt.Errorf("band %v is not an infrared band", band)
+	}
+
+	bounds := img.Bounds()
+	mask := image.NewNRGBA(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if cal.Kelvin(pixelValue(img, x, y)) < threshold {
+				mask.Set(x, y, c)
+			}
+		}
+	}
+
+	return mask, nil
+}
@@ -0,0 +1,74 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestCalibration tests conversion between pixel values and temperatures.
+func TestCalibration(t *testing.T) {
+	c, ok := Band(13).Calibration()
+	if !ok {
+		t.Fatal("Expected band 13 to be calibrated")
+	}
+
+	if k := c.Kelvin(0); k != c.Warm {
+		t.Errorf("Expected %v, received %v", c.Warm, k)
+	}
+	if k := c.Kelvin(255); k != c.Cold {
+		t.Errorf("Expected %v, received %v", c.Cold, k)
+	}
+	if v := c.Value(c.Kelvin(100)); v != 100 {
+		t.Errorf("Expected 100, received %v", v)
+	}
+
+	if _, ok := Band(3).Calibration(); ok {
+		t.Errorf("Expected band 3 to have no calibration")
+	}
+}
+
+// TestTemperatureMask tests that only pixels colder than the threshold
+// are masked.
+func TestTemperatureMask(t *testing.T) {
+	c, _ := Band(13).Calibration()
+
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(0, 0, color.Gray{c.Value(Kelvin(-70))})
+	img.SetGray(1, 0, color.Gray{c.Value(Kelvin(-10))})
+
+	temperature, err := Temperature(Band(13), img, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(Celsius(temperature)+70) > 1 {
+		t.Errorf("Expected about -70°C, received %v", Celsius(temperature))
+	}
+
+	red := color.NRGBA{255, 0, 0, 255}
+	mask, err := TemperatureMask(Band(13), img, Kelvin(-60), red)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mask.NRGBAAt(0, 0) != red {
+		t.Errorf("Expected the cold pixel to be masked")
+	}
+	if mask.NRGBAAt(1, 0).A != 0 {
+		t.Errorf("Expected the warm pixel to be transparent")
+	}
+}
+
+// TestTemperatureAtProvider tests that points are located using the
+// Provider's Projection.
+func TestTemperatureAtProvider(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+
+	if _, err := TemperatureAt(fakeProvider{}, Band(13), img, 0, -75.2); err != nil {
+		t.Errorf("Expected the point below the satellite to be visible, received %v", err)
+	}
+	if _, err := TemperatureAt(nil, Band(13), img, 0, -75.2); err == nil {
+		t.Error("Expected an error for a point on the far side of the Earth")
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/tscott0/himago/cmd
 
-go 1.12
+go 1.24
 
 require github.com/tscott0/himago v0.0.0-20170429154516-e46b8801789d // indirect
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"image/color"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -13,49 +14,212 @@ type Color struct {
 	color.NRGBA
 }
 
-// String returns the color as a string
+// String returns the color as #rrggbb, or #rrggbbaa if it is not opaque.
+// This is a format that Set accepts as input.
 func (c *Color) String() string {
-	return fmt.Sprintf("#%02x%02x%02x,", c.R, c.G, c.B)
+	if c.A != 255 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
 }
 
-// Set accepts a string of digits making a hex number.
-// Must be prefixed with a #
-// eg. #FFFFFF or #ab12ab
+// Set accepts a colour in any of these forms, ignoring case and
+// surrounding spaces:
+//
+//	#rgb, #rgba, #rrggbb or #rrggbbaa
+//	rgb(255, 128, 0) or rgba(255, 128, 0, 0.5)
+//	rgb(100% 50% 0% / 50%)
+//	hsl(30, 100%, 50%) or hsla(30deg, 100%, 50%, 0.5)
+//	a CSS colour name such as orange, or transparent
+//
+// Omitted alpha is opaque.
 func (c *Color) Set(value string) error {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	var parsed color.NRGBA
+	var err error
 
-	if !strings.HasPrefix(value, "#") {
-		return errors.New("Colours must start with a #")
+	switch {
+	case strings.HasPrefix(value, "#"):
+		parsed, err = parseHexColor(value[1:])
+	case strings.HasPrefix(value, "rgb"):
+		parsed, err = parseRGBColor(value)
+	case strings.HasPrefix(value, "hsl"):
+		parsed, err = parseHSLColor(value)
+	default:
+		var ok bool
+		if parsed, ok = cssColors[value]; !ok {
+			err = errors.New("Colours must be hex (#rrggbb), rgb(), hsl() or a CSS colour name")
+		}
 	}
 
-	chars := strings.Split(value, "")
+	if err != nil {
+		return err
+	}
+
+	c.NRGBA = parsed
+	return nil
+}
+
+// parseHexColor parses the digits of #rgb, #rgba, #rrggbb or #rrggbbaa.
+func parseHexColor(digits string) (color.NRGBA, error) {
+	// Short forms repeat each digit
+	if len(digits) == 3 || len(digits) == 4 {
+		var long strings.Builder
+		for _, d := range digits {
+			long.WriteRune(d)
+			long.WriteRune(d)
+		}
+		digits = long.String()
+	}
 
-	if len(chars) != 7 {
-		return errors.New("Colour string is not the right length")
+	if len(digits) == 6 {
+		digits += "ff"
 	}
 
-	rString := strings.Join(chars[1:3], "")
-	gString := strings.Join(chars[3:5], "")
-	bString := strings.Join(chars[5:7], "")
+	if len(digits) != 8 {
+		return color.NRGBA{}, errors.New("Colour string is not the right length")
+	}
 
-	r, err := strconv.ParseUint(rString, 16, 32)
+	v, err := strconv.ParseUint(digits, 16, 32)
 	if err != nil {
-		return errors.New("Invalid hexadecimal number (red)")
+		return color.NRGBA{}, errors.New("Invalid hexadecimal number")
+	}
+
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// colorArgs returns the arguments of a CSS colour function such as
+// rgb(1, 2, 3) or rgb(1 2 3 / 50%). The alpha, if any, is always last.
+// Empty arguments, such as a missing alpha after the slash, are rejected.
+func colorArgs(value string, names ...string) ([]string, error) {
+	for _, name := range names {
+		if strings.HasPrefix(value, name+"(") && strings.HasSuffix(value, ")") {
+			return splitColorArgs(value[len(name)+1 : len(value)-1])
+		}
 	}
 
-	g, err := strconv.ParseUint(gString, 16, 32)
+	return nil, fmt.Errorf("Invalid colour %q", value)
+}
+
+// splitColorArgs splits the comma separated or the space separated form
+// of a colour function's arguments.
+func splitColorArgs(inner string) ([]string, error) {
+	if strings.Contains(inner, ",") {
+		args := strings.Split(inner, ",")
+		for i, arg := range args {
+			args[i] = strings.TrimSpace(arg)
+			if args[i] == "" {
+				return nil, errors.New("Empty colour component")
+			}
+		}
+		return args, nil
+	}
+
+	parts := strings.Split(inner, "/")
+	if len(parts) > 2 {
+		return nil, errors.New("Colour functions take one alpha after a /")
+	}
+
+	args := strings.Fields(parts[0])
+	if len(parts) == 2 {
+		alpha := strings.Fields(parts[1])
+		if len(alpha) != 1 {
+			return nil, errors.New("Colour functions take one alpha after a /")
+		}
+		args = append(args, alpha[0])
+	}
+
+	return args, nil
+}
+
+// parseNumber parses a number or percentage, returning a fraction of max.
+func parseNumber(s string, max float64) (float64, error) {
+	scale := max
+	if strings.HasSuffix(s, "%") {
+		s = strings.TrimSuffix(s, "%")
+		scale = 100
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
 	if err != nil {
-		return errors.New("Invalid hexadecimal number (green)")
+		return 0, fmt.Errorf("Invalid colour component %q", s)
 	}
 
-	b, err := strconv.ParseUint(bString, 16, 32)
+	return math.Max(0, math.Min(1, v/scale)), nil
+}
+
+// parseAlpha parses an optional alpha of 0 to 1 or a percentage.
+func parseAlpha(args []string, n int) (uint8, error) {
+	if len(args) == n {
+		return 255, nil
+	}
+	if len(args) != n+1 {
+		return 0, errors.New("Colour functions take 3 components and an optional alpha")
+	}
+
+	a, err := parseNumber(args[n], 1)
+	return uint8(math.Round(a * 255)), err
+}
+
+// parseRGBColor parses rgb() and rgba().
+func parseRGBColor(value string) (color.NRGBA, error) {
+	args, err := colorArgs(value, "rgb", "rgba")
 	if err != nil {
-		return errors.New("Invalid hexadecimal number (blue)")
+		return color.NRGBA{}, err
 	}
 
-	c.R = uint8(r)
-	c.G = uint8(g)
-	c.B = uint8(b)
-	c.A = 255
+	var c color.NRGBA
+	if c.A, err = parseAlpha(args, 3); err != nil {
+		return c, err
+	}
 
-	return nil
+	channels := []*uint8{&c.R, &c.G, &c.B}
+	for i, channel := range channels {
+		v, err := parseNumber(args[i], 255)
+		if err != nil {
+			return c, err
+		}
+		*channel = uint8(math.Round(v * 255))
+	}
+
+	return c, nil
+}
+
+// parseHSLColor parses hsl() and hsla().
+func parseHSLColor(value string) (color.NRGBA, error) {
+	args, err := colorArgs(value, "hsl", "hsla")
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+
+	var c color.NRGBA
+	if c.A, err = parseAlpha(args, 3); err != nil {
+		return c, err
+	}
+
+	h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+	if err != nil {
+		return c, fmt.Errorf("Invalid hue %q", args[0])
+	}
+	s, err := parseNumber(args[1], 1)
+	if err != nil {
+		return c, err
+	}
+	l, err := parseNumber(args[2], 1)
+	if err != nil {
+		return c, err
+	}
+
+	// From the CSS Color Module Level 4 hslToRgb algorithm
+	h = math.Mod(math.Mod(h, 360)+360, 360)
+	a := s * math.Min(l, 1-l)
+	f := func(n float64) uint8 {
+		k := math.Mod(n+h/30, 12)
+		v := l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+		return uint8(math.Round(v * 255))
+	}
+
+	c.R, c.G, c.B = f(0), f(8), f(4)
+	return c, nil
 }
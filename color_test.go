@@ -0,0 +1,150 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestColorSet tests every accepted colour format.
+func TestColorSet(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		out  color.NRGBA
+	}{
+		{"Hex", "#1793d1", color.NRGBA{0x17, 0x93, 0xd1, 0xff}},
+		{"Upper case hex", "#FFCC00", color.NRGBA{0xff, 0xcc, 0x00, 0xff}},
+		{"Trailing space", "#5f99cf ", color.NRGBA{0x5f, 0x99, 0xcf, 0xff}},
+		{"Short hex", "#f80", color.NRGBA{0xff, 0x88, 0x00, 0xff}},
+		{"Short hex with alpha", "#f808", color.NRGBA{0xff, 0x88, 0x00, 0x88}},
+		{"Hex with alpha", "#11223380", color.NRGBA{0x11, 0x22, 0x33, 0x80}},
+		{"rgb", "rgb(255, 69, 0)", color.NRGBA{255, 69, 0, 255}},
+		{"rgba", "rgba(255,69,0,0.5)", color.NRGBA{255, 69, 0, 128}},
+		{"rgb percentages", "rgb(100% 50% 0% / 25%)", color.NRGBA{255, 128, 0, 64}},
+		{"hsl", "hsl(120, 100%, 25%)", color.NRGBA{0, 128, 0, 255}},
+		{"hsla", "hsla(240deg, 100%, 50%, 0)", color.NRGBA{0, 0, 255, 0}},
+		{"Negative hue", "hsl(-120, 100%, 50%)", color.NRGBA{0, 0, 255, 255}},
+		{"CSS name", "Orange", color.NRGBA{0xff, 0xa5, 0x00, 0xff}},
+		{"CSS green is not X11 green", "green", color.NRGBA{0x00, 0x80, 0x00, 0xff}},
+		{"Transparent", "transparent", color.NRGBA{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Color
+			if err := c.Set(tt.in); err != nil {
+				t.Fatal(err)
+			}
+			if c.NRGBA != tt.out {
+				t.Errorf("Expected %v from %q, received %v", tt.out, tt.in, c.NRGBA)
+			}
+		})
+	}
+}
+
+// TestColorSetInvalid tests that invalid colours are rejected and leave
+// the Color unchanged.
+func TestColorSetInvalid(t *testing.T) {
+	invalid := []string{"", "#", "#12345", "#1234567", "#ggg", "rgb(1, 2)", "rgb(1, 2, 3, 4, 5)", "rgb(a, b, c)", "hsl(x, 1%, 1%)", "nocolour", "rgb(1, 2, 3",
+		"rgb(1 2 3 / )", "rgb(1, 2,, 3)", "rgb(1, 2, 3,)", "rgb(1 2 3 / 4 / 5)", "hsl(1 2% 3% /)"}
+
+	for _, in := range invalid {
+		t.Run(in, func(t *testing.T) {
+			c := Color{color.NRGBA{1, 2, 3, 4}}
+			if err := c.Set(in); err == nil {
+				t.Errorf("Set(%q) did not return an error", in)
+			}
+			if c.NRGBA != (color.NRGBA{1, 2, 3, 4}) {
+				t.Errorf("Set(%q) changed the colour to %v", in, c.NRGBA)
+			}
+		})
+	}
+}
+
+// TestColorString tests that String has no trailing comma and that its
+// output is accepted by Set.
+func TestColorString(t *testing.T) {
+	tests := []struct {
+		in  color.NRGBA
+		out string
+	}{
+		{color.NRGBA{0x33, 0x33, 0x33, 0xff}, "#333333"},
+		{color.NRGBA{0xff, 0x45, 0x00, 0x80}, "#ff450080"},
+		{color.NRGBA{}, "#00000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			c := Color{tt.in}
+			if c.String() != tt.out {
+				t.Errorf("Expected %q, received %q", tt.out, c.String())
+			}
+
+			var parsed Color
+			if err := parsed.Set(c.String()); err != nil || parsed != c {
+				t.Errorf("Expected %v after a round trip, received %v (%v)", c.NRGBA, parsed.NRGBA, err)
+			}
+		})
+	}
+}
+
+// TestStitchAlpha tests that the alpha of fg and bg is kept when stitching.
+func TestStitchAlpha(t *testing.T) {
+	// Opaque on the left, transparent on the right
+	img := image.NewNRGBA(image.Rect(0, 0, defaultTileSize, defaultTileSize))
+	for y := 0; y < defaultTileSize; y++ {
+		for x := 0; x < defaultTileSize/2; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+		}
+	}
+
+	fg := Color{color.NRGBA{255, 0, 0, 128}}
+	bg := Color{color.NRGBA{0, 0, 255, 64}}
+
+	out := Stitch(Band(13), [][]Tile{{{img}}}, bg, fg)
+
+	tests := []struct {
+		name string
+		x    int
+		out  color.NRGBA
+	}{
+		// fg at half opacity over bg at quarter opacity
+		{"Foreground", 10, color.NRGBA{R: 204, B: 50, A: 160}},
+		{"Background", defaultTileSize - 10, color.NRGBA{B: 255, A: 64}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := color.NRGBAModel.Convert(out.At(tt.x, 10)).(color.NRGBA)
+			if channelDiff(c.R, tt.out.R) > 2 || channelDiff(c.G, tt.out.G) > 2 || channelDiff(c.B, tt.out.B) > 2 || channelDiff(c.A, tt.out.A) > 2 {
+				t.Errorf("Expected %v, received %v", tt.out, c)
+			}
+		})
+	}
+}
+
+// TestStitchKeepsTiles tests that stitching a band leaves the Tiles
+// unchanged, so the same Tiles can be stitched again in other colours.
+func TestStitchKeepsTiles(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.SetNRGBA(0, 0, color.NRGBA{255, 255, 255, 255})
+	tiles := [][]Tile{{{img}}}
+
+	Stitch(Band(13), tiles, Color{}, Color{color.NRGBA{255, 0, 0, 255}})
+
+	if tiles[0][0].Image != image.Image(img) {
+		t.Fatalf("Expected the Tile to keep its image")
+	}
+	if c := img.NRGBAAt(0, 0); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected the Tile to stay white, received %v", c)
+	}
+}
+
+// channelDiff returns the absolute difference between two channels.
+func channelDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,117 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// ColorStop is a single colour at a Position between 0 and 1 in a ColorMap.
+type ColorStop struct {
+	Position float64
+	Color    color.NRGBA
+}
+
+// ColorMap maps values between 0 and 1 to colours by interpolating linearly
+// between its stops. Stops must be sorted by Position.
+type ColorMap struct {
+	Name  string
+	Stops []ColorStop
+}
+
+// Predefined colour maps, used as the defaults for each Band.
+var (
+	// Greyscale maps 0 to black and 1 to white.
+	Greyscale = ColorMap{"grey", []ColorStop{
+		{0, color.NRGBA{0, 0, 0, 255}},
+		{1, color.NRGBA{255, 255, 255, 255}},
+	}}
+
+	// WaterVapour shows dry air as brown and moist air as white via blue,
+	// as is conventional for the water vapour bands.
+	WaterVapour = ColorMap{"water-vapour", []ColorStop{
+		{0, color.NRGBA{80, 50, 20, 255}},
+		{0.4, color.NRGBA{20, 60, 160, 255}},
+		{0.7, color.NRGBA{120, 180, 230, 255}},
+		{1, color.NRGBA{255, 255, 255, 255}},
+	}}
+
+	// EnhancedIR is greyscale for warm values and highlights the coldest
+	// cloud tops in colour.
+	EnhancedIR = ColorMap{"enhanced-ir", []ColorStop{
+		{0, color.NRGBA{0, 0, 0, 255}},
+		{0.6, color.NRGBA{200, 200, 200, 255}},
+		{0.7, color.NRGBA{0, 120, 255, 255}},
+		{0.8, color.NRGBA{0, 220, 0, 255}},
+		{0.9, color.NRGBA{255, 220, 0, 255}},
+		{1, color.NRGBA{255, 0, 0, 255}},
+	}}
+
+	// Difference is blue for negative values, white at 0.5 and red for
+	// positive values. It is intended for signed differences.
+	Difference = ColorMap{"difference", []ColorStop{
+		{0, color.NRGBA{0, 0, 255, 255}},
+		{0.5, color.NRGBA{255, 255, 255, 255}},
+		{1, color.NRGBA{255, 0, 0, 255}},
+	}}
+)
+
+// ColorMaps lists the predefined colour maps by name.
+var ColorMaps = map[string]ColorMap{
+	Greyscale.Name:   Greyscale,
+	WaterVapour.Name: WaterVapour,
+	EnhancedIR.Name:  EnhancedIR,
+	Difference.Name:  Difference,
+}
+
+// At returns the colour for v. Values outside 0 to 1 are clamped.
+func (m ColorMap) At(v float64) color.NRGBA {
+	if len(m.Stops) == 0 {
+		return color.NRGBA{}
+	}
+
+	v = math.Max(0, math.Min(1, v))
+
+	if v <= m.Stops[0].Position {
+		return m.Stops[0].Color
+	}
+
+	for i := 1; i < len(m.Stops); i++ {
+		lo, hi := m.Stops[i-1], m.Stops[i]
+		if v > hi.Position {
+			continue
+		}
+
+		t := (v - lo.Position) / (hi.Position - lo.Position)
+		return color.NRGBA{
+			lerp8(lo.Color.R, hi.Color.R, t),
+			lerp8(lo.Color.G, hi.Color.G, t),
+			lerp8(lo.Color.B, hi.Color.B, t),
+			lerp8(lo.Color.A, hi.Color.A, t),
+		}
+	}
+
+	return m.Stops[len(m.Stops)-1].Color
+}
+
+// lerp8 linearly interpolates between two 8-bit values.
+func lerp8(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
+
+// Apply returns img recoloured by the map, using the intensity of each
+// pixel composited over black as the value. img should be a band drawn
+// with a white foreground on a black background.
+func (m ColorMap) Apply(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			v := pixelValue(img, bounds.Min.X+x, bounds.Min.Y+y)
+			out.SetNRGBA(x, y, m.At(float64(v)/255))
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,47 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestColorMapAt tests interpolation and clamping of a ColorMap.
+func TestColorMapAt(t *testing.T) {
+	colorMapTests := []struct {
+		name string
+		in   float64
+		out  color.NRGBA
+	}{
+		{"Start", 0, color.NRGBA{0, 0, 0, 255}},
+		{"Middle", 0.5, color.NRGBA{128, 128, 128, 255}},
+		{"End", 1, color.NRGBA{255, 255, 255, 255}},
+		{"Below", -1, color.NRGBA{0, 0, 0, 255}},
+		{"Above", 2, color.NRGBA{255, 255, 255, 255}},
+	}
+
+	for _, ct := range colorMapTests {
+		t.Run(ct.name, func(t *testing.T) {
+			if c := Greyscale.At(ct.in); c != ct.out {
+				t.Errorf("Expected %v, received %v", ct.out, c)
+			}
+		})
+	}
+}
+
+// TestColorMapApply tests that each pixel's intensity is mapped through
+// the ColorMap.
+func TestColorMapApply(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 3, 1))
+	img.SetGray(1, 0, color.Gray{128})
+	img.SetGray(2, 0, color.Gray{255})
+
+	out := Difference.Apply(img)
+
+	expected := []color.NRGBA{{0, 0, 255, 255}, {255, 255, 255, 255}, {255, 0, 0, 255}}
+	for x, e := range expected {
+		if c := out.NRGBAAt(x, 0); channelDiff(c.R, e.R) > 2 || channelDiff(c.G, e.G) > 2 || channelDiff(c.B, e.B) > 2 {
+			t.Errorf("Expected %v at %v, received %v", e, x, c)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package himago
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// BlendMode is how a Layer is combined with the layers beneath it.
+// The modes follow the W3C Compositing and Blending specification.
+type BlendMode int
+
+// Blend modes.
+const (
+	// BlendNormal draws the layer over those beneath it.
+	BlendNormal BlendMode = iota
+
+	// BlendMultiply darkens, white leaves the layers beneath unchanged.
+	BlendMultiply
+
+	// BlendScreen lightens, black leaves the layers beneath unchanged.
+	BlendScreen
+
+	// BlendOverlay multiplies dark areas and screens light areas of the
+	// layers beneath, increasing contrast.
+	BlendOverlay
+
+	// BlendAdd adds the colours, clipping at white.
+	BlendAdd
+
+	// BlendDifference subtracts the darker colour from the lighter.
+	BlendDifference
+)
+
+var blendModeNames = []string{"normal", "multiply", "screen", "overlay", "add", "difference"}
+
+// String returns the name of the blend mode.
+func (m *BlendMode) String() string {
+	if *m < 0 || int(*m) >= len(blendModeNames) {
+		return "unknown"
+	}
+	return blendModeNames[*m]
+}
+
+// Set accepts the name of a blend mode, ignoring case.
+// Implements the flag.Value interface.
+func (m *BlendMode) Set(value string) error {
+	for i, name := range blendModeNames {
+		if strings.EqualFold(strings.TrimSpace(value), name) {
+			*m = BlendMode(i)
+			return nil
+		}
+	}
+
+	return errors.New("Blend mode must be one of " + strings.Join(blendModeNames, ", "))
+}
+
+// blend returns the blended value of a backdrop and source channel,
+// each between 0 and 1.
+func (m BlendMode) blend(b, s float64) float64 {
+	switch m {
+	case BlendMultiply:
+		return b * s
+	case BlendScreen:
+		return b + s - b*s
+	case BlendOverlay:
+		if b <= 0.5 {
+			return 2 * b * s
+		}
+		return 1 - 2*(1-b)*(1-s)
+	case BlendAdd:
+		return math.Min(1, b+s)
+	case BlendDifference:
+		return math.Abs(b - s)
+	}
+
+	return s
+}
+
+// Layer is one image in a Composite, such as a band from Stitch, a ColorMap
+// applied with ColorMap.Apply, paths drawn with DrawPaths onto a
+// transparent image, or a photo such as night-time city lights.
+type Layer struct {
+	// Image may be nil, in which case the Layer is skipped.
+	Image image.Image
+
+	// Opacity scales the alpha of Image and is clamped between 0 and 1.
+	// If nil the Layer is opaque, see the Opacity function.
+	Opacity *float64
+
+	Blend BlendMode
+}
+
+// Opacity returns a pointer to v for use as Layer.Opacity.
+func Opacity(v float64) *float64 {
+	return &v
+}
+
+// opacity returns the Layer's Opacity clamped between 0 and 1.
+func (l Layer) opacity() float64 {
+	if l.Opacity == nil || math.IsNaN(*l.Opacity) {
+		return 1
+	}
+
+	return math.Max(0, math.Min(1, *l.Opacity))
+}
+
+// Composite blends layers from the bottom, layers[0], to the top. The
+// result is the size of the bottom layer with an Image. Layers of a
+// different size are scaled to fit with ScaleImage, so full-disk images at
+// different zooms can be combined. If no layer has an Image the result is
+// empty.
+func Composite(layers ...Layer) *image.NRGBA {
+	var size image.Rectangle
+	for _, layer := range layers {
+		if layer.Image != nil {
+			bounds := layer.Image.Bounds()
+			size = image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+			break
+		}
+	}
+
+	out := image.NewNRGBA(size)
+
+	for _, layer := range layers {
+		src := layer.Image
+		if src == nil {
+			continue
+		}
+		if src.Bounds().Dx() != size.Dx() || src.Bounds().Dy() != size.Dy() {
+			src = ScaleImage(src, size)
+		}
+
+		opacity := layer.opacity()
+
+		min := src.Bounds().Min
+		for y := 0; y < size.Dy(); y++ {
+			for x := 0; x < size.Dx(); x++ {
+				s := color.NRGBAModel.Convert(src.At(min.X+x, min.Y+y)).(color.NRGBA)
+				out.SetNRGBA(x, y, layer.Blend.composite(out.NRGBAAt(x, y), s, opacity))
+			}
+		}
+	}
+
+	return out
+}
+
+// composite draws source over backdrop with the blend mode. Where the
+// backdrop is transparent the source is drawn unblended.
+func (m BlendMode) composite(backdrop, source color.NRGBA, opacity float64) color.NRGBA {
+	as := float64(source.A) / 255 * opacity
+	ab := float64(backdrop.A) / 255
+
+	ao := as + ab*(1-as)
+	if ao == 0 {
+		return color.NRGBA{}
+	}
+
+	channel := func(b, s uint8) uint8 {
+		cb, cs := float64(b)/255, float64(s)/255
+		mixed := (1-ab)*cs + ab*m.blend(cb, cs)
+		co := as*mixed + (1-as)*ab*cb
+		return uint8(math.Round(co / ao * 255))
+	}
+
+	return color.NRGBA{
+		channel(backdrop.R, source.R),
+		channel(backdrop.G, source.G),
+		channel(backdrop.B, source.B),
+		uint8(math.Round(ao * 255)),
+	}
+}
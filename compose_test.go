@@ -0,0 +1,85 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestBlendModeSet tests that every blend mode can be set by name,
+// ignoring case, and that unknown names are rejected.
+func TestBlendModeSet(t *testing.T) {
+	for i, name := range []string{"normal", "Multiply", "SCREEN", "overlay", " add", "difference"} {
+		t.Run(name, func(t *testing.T) {
+			var m BlendMode
+			if err := m.Set(name); err != nil {
+				t.Fatal(err)
+			}
+			if m != BlendMode(i) {
+				t.Errorf("Expected %v, received %v", i, int(m))
+			}
+			if m.String() != blendModeNames[i] {
+				t.Errorf("Expected %q, received %q", blendModeNames[i], m.String())
+			}
+		})
+	}
+
+	var m BlendMode
+	if err := m.Set("dodge"); err == nil {
+		t.Error("Expected an error for an unknown blend mode")
+	}
+}
+
+// uniformImage returns a 4x4 image of c.
+func uniformImage(c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// TestComposite tests each blend mode and opacity with an orange layer
+// over a grey one.
+func TestComposite(t *testing.T) {
+	grey := uniformImage(color.NRGBA{128, 128, 128, 255})
+	orange := uniformImage(color.NRGBA{255, 128, 0, 255})
+
+	compositeTests := []struct {
+		name   string
+		layers []Layer
+		out    color.NRGBA
+	}{
+		{"Normal", []Layer{{Image: grey}, {Image: orange}}, color.NRGBA{255, 128, 0, 255}},
+		{"Half opacity", []Layer{{Image: grey}, {Image: orange, Opacity: Opacity(0.5)}}, color.NRGBA{192, 128, 64, 255}},
+		{"Zero opacity", []Layer{{Image: grey}, {Image: orange, Opacity: Opacity(0)}}, color.NRGBA{128, 128, 128, 255}},
+		{"Opacity clamped", []Layer{{Image: grey}, {Image: orange, Opacity: Opacity(2)}}, color.NRGBA{255, 128, 0, 255}},
+		{"Nil bottom layer", []Layer{{}, {Image: grey}, {Image: orange, Blend: BlendMultiply}}, color.NRGBA{128, 64, 0, 255}},
+		{"Multiply", []Layer{{Image: grey}, {Image: orange, Blend: BlendMultiply}}, color.NRGBA{128, 64, 0, 255}},
+		{"Screen", []Layer{{Image: grey}, {Image: orange, Blend: BlendScreen}}, color.NRGBA{255, 192, 128, 255}},
+		{"Overlay", []Layer{{Image: grey}, {Image: orange, Blend: BlendOverlay}}, color.NRGBA{255, 129, 0, 255}},
+		{"Add", []Layer{{Image: grey}, {Image: orange, Blend: BlendAdd}}, color.NRGBA{255, 255, 128, 255}},
+		{"Difference", []Layer{{Image: grey}, {Image: orange, Blend: BlendDifference}}, color.NRGBA{127, 0, 128, 255}},
+		{"Blend over transparent", []Layer{{Image: orange, Blend: BlendMultiply}}, color.NRGBA{255, 128, 0, 255}},
+		{"Transparent layer", []Layer{{Image: grey}, {Image: uniformImage(color.NRGBA{255, 0, 0, 0}), Blend: BlendDifference}}, color.NRGBA{128, 128, 128, 255}},
+		{"Scaled layer", []Layer{{Image: grey}, {Image: uniformImage(color.NRGBA{255, 128, 0, 255}).SubImage(image.Rect(1, 1, 3, 3))}}, color.NRGBA{255, 128, 0, 255}},
+	}
+
+	for _, ct := range compositeTests {
+		t.Run(ct.name, func(t *testing.T) {
+			out := Composite(ct.layers...)
+			if out.Bounds() != image.Rect(0, 0, 4, 4) {
+				t.Fatalf("Expected a 4x4 image, received %v", out.Bounds())
+			}
+
+			c := out.NRGBAAt(2, 2)
+			if channelDiff(c.R, ct.out.R) > 1 || channelDiff(c.G, ct.out.G) > 1 || channelDiff(c.B, ct.out.B) > 1 || channelDiff(c.A, ct.out.A) > 1 {
+				t.Errorf("Expected %v, received %v", ct.out, c)
+			}
+		})
+	}
+
+	if out := Composite(Layer{}); !out.Bounds().Empty() {
+		t.Errorf("Expected an empty image without any Images, received %v", out.Bounds())
+	}
+}
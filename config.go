@@ -0,0 +1,276 @@
+package himago
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are the values that a config file or preset can set. Empty
+// fields are left unchanged when Settings are applied.
+type Settings struct {
+	// Band is a number or name accepted by Band.Set, such as "13" or "B13".
+	Band string `json:"band,omitempty"`
+	Zoom int    `json:"zoom,omitempty"`
+
+	// BG and FG are colours accepted by Color.Set.
+	BG string `json:"bg,omitempty"`
+	FG string `json:"fg,omitempty"`
+
+	Output string `json:"output,omitempty"`
+
+	// Cache is a directory for a Cache.
+	Cache string `json:"cache,omitempty"`
+
+	// RateLimit is a duration such as "500ms", see Fetcher.RateLimit.
+	RateLimit string `json:"rateLimit,omitempty"`
+}
+
+// Config is the contents of a config file: default Settings and named
+// presets. A config file is JSON, for example
+//
+//	{
+//	  "zoom": 2,
+//	  "cache": "/home/me/.cache/himago",
+//	  "rateLimit": "200ms",
+//	  "presets": {
+//	    "night": {"band": "ir-10.4", "bg": "#000000", "fg": "#ff8800"}
+//	  }
+//	}
+type Config struct {
+	Settings
+	Presets map[string]Settings `json:"presets,omitempty"`
+}
+
+// Presets are the colour schemes from _examples/EXAMPLES.md. A preset in
+// a config file with the same name replaces the built-in one.
+var Presets = map[string]Settings{
+	"arch":        {Band: "3", Zoom: 2, BG: "#333333", FG: "#1793d1"},
+	"dominos":     {Band: "8", Zoom: 2, BG: "#0b648f", FG: "#e21737"},
+	"facebook":    {Band: "12", Zoom: 2, BG: "#3b5998", FG: "#ffffff"},
+	"flickr":      {Band: "10", Zoom: 2, BG: "#0063dc", FG: "#ff0084"},
+	"google-plus": {Band: "10", Zoom: 2, BG: "#dd4b39", FG: "#ffffff"},
+	"heineken":    {Band: "12", Zoom: 2, BG: "#00a100", FG: "#ff2b00"},
+	"ikea":        {Band: "11", Zoom: 2, BG: "#003399", FG: "#ffcc00"},
+	"lego":        {Band: "15", Zoom: 2, BG: "#d11013", FG: "#f6ec35"},
+	"python":      {Band: "13", Zoom: 2, BG: "#ffde57", FG: "#4584b6"},
+	"reddit":      {Band: "13", Zoom: 2, BG: "#5f99cf", FG: "#ff4500"},
+}
+
+// Options are fully resolved settings, ready to use.
+//
+// Band, Zoom, BG and FG are flag.Values, so registering them as flags
+// after resolving the config lets flags override it, see PresetArg.
+type Options struct {
+	Band      Band
+	Zoom      Zoom
+	BG        Color
+	FG        Color
+	Output    string
+	Cache     string
+	RateLimit time.Duration
+}
+
+// DefaultOptions are used for anything not set by a config or preset:
+// the true-colour image at zoom 2 in white on black, written to output.png.
+// These match the defaults in the README.
+func DefaultOptions() Options {
+	return Options{
+		Zoom:   Zoom(2),
+		BG:     Color{color.NRGBA{0, 0, 0, 255}},
+		FG:     Color{color.NRGBA{255, 255, 255, 255}},
+		Output: "output.png",
+	}
+}
+
+// ConfigPath returns the location of the config file,
+// himago/config.json in the user's config directory. On Linux this is
+// $XDG_CONFIG_HOME, or ~/.config if that is not set.
+func ConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "himago", "config.json"), nil
+}
+
+// LoadConfig reads a config file. A missing file is not an error, an
+// empty Config is returned.
+func LoadConfig(fileName string) (*Config, error) {
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid config %v: %v", fileName, err)
+	}
+
+	// Presets are looked up ignoring case so names must differ by more
+	names := map[string]string{}
+	for n := range c.Presets {
+		if other, ok := names[strings.ToLower(n)]; ok {
+			return nil, fmt.Errorf("invalid config %v: presets %q and %q differ only in case", fileName, other, n)
+		}
+		names[strings.ToLower(n)] = n
+	}
+
+	return &c, nil
+}
+
+// PresetArg returns the value of a --preset or -preset flag in args, in
+// either the "--preset name" or "--preset=name" form, or "" if there is
+// none. The preset has to be known before the other flags are registered
+// so that they can override it:
+//
+//	opts, err := config.Options(himago.PresetArg(os.Args[1:]))
+//	flag.String("preset", "", "named colour scheme")
+//	flag.Var(&opts.Band, "b", "band")
+//	flag.Parse()
+func PresetArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		switch {
+		case name == "preset" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(name, "preset="):
+			return strings.TrimPrefix(name, "preset=")
+		}
+	}
+
+	return ""
+}
+
+// Preset returns the named preset, ignoring case. Presets in the config
+// take precedence over the built-in Presets.
+func (c *Config) Preset(name string) (Settings, bool) {
+	name = strings.ToLower(name)
+
+	for n, s := range c.Presets {
+		if strings.ToLower(n) == name {
+			return s, true
+		}
+	}
+
+	s, ok := Presets[name]
+	return s, ok
+}
+
+// PresetNames returns the names of every preset, sorted.
+func (c *Config) PresetNames() []string {
+	seen := map[string]bool{}
+	for n := range Presets {
+		seen[n] = true
+	}
+	for n := range c.Presets {
+		seen[strings.ToLower(n)] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Options resolves DefaultOptions, then the config's Settings, then the
+// named preset if there is one, each overriding the last.
+func (c *Config) Options(preset string) (Options, error) {
+	opts := DefaultOptions()
+
+	if err := c.Settings.Apply(&opts); err != nil {
+		return opts, err
+	}
+
+	if preset != "" {
+		s, ok := c.Preset(preset)
+		if !ok {
+			return opts, fmt.Errorf("unknown preset %q, expected one of %v", preset, strings.Join(c.PresetNames(), ", "))
+		}
+		if err := s.Apply(&opts); err != nil {
+			return opts, fmt.Errorf("preset %v: %v", preset, err)
+		}
+	}
+
+	return opts, nil
+}
+
+// Apply sets every field of opts that s sets.
+func (s Settings) Apply(opts *Options) error {
+	if s.Band != "" {
+		if err := opts.Band.Set(s.Band); err != nil {
+			return err
+		}
+	}
+
+	if s.Zoom != 0 {
+		if err := opts.Zoom.Set(strconv.Itoa(s.Zoom)); err != nil {
+			return err
+		}
+	}
+
+	if s.BG != "" {
+		if err := opts.BG.Set(s.BG); err != nil {
+			return err
+		}
+	}
+
+	if s.FG != "" {
+		if err := opts.FG.Set(s.FG); err != nil {
+			return err
+		}
+	}
+
+	if s.Output != "" {
+		opts.Output = s.Output
+	}
+
+	if s.Cache != "" {
+		opts.Cache = s.Cache
+	}
+
+	if s.RateLimit != "" {
+		d, err := time.ParseDuration(s.RateLimit)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid rate limit %q", s.RateLimit)
+		}
+		opts.RateLimit = d
+	}
+
+	return nil
+}
+
+// Fetcher returns a Fetcher using the Cache and RateLimit of the Options.
+func (o Options) Fetcher() (*Fetcher, error) {
+	f := &Fetcher{RateLimit: o.RateLimit}
+
+	if o.Cache != "" {
+		cache, err := NewCache(o.Cache)
+		if err != nil {
+			return nil, err
+		}
+		f.Cache = cache
+	}
+
+	return f, nil
+}
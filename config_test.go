@@ -0,0 +1,157 @@
+package himago
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file to a temporary directory and returns
+// its name.
+func writeConfig(t *testing.T, config string) string {
+	fileName := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(fileName, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return fileName
+}
+
+// TestConfigOptions tests that Options are resolved from the defaults,
+// then the config, then a preset from the config or the built-in Presets.
+func TestConfigOptions(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, `{
+		"zoom": 3,
+		"bg": "#102030",
+		"rateLimit": "250ms",
+		"presets": {"Night": {"band": "ir-10.4", "fg": "#ff8800"}, "arch": {"band": "4"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	optionsTests := []struct {
+		name   string
+		preset string
+		band   Band
+		zoom   Zoom
+		bg, fg string
+	}{
+		{"Config defaults", "", Band(0), Zoom(3), "#102030", "#ffffff"},
+		{"Config preset", "night", Band(13), Zoom(3), "#102030", "#ff8800"},
+		{"Config replaces built-in", "arch", Band(4), Zoom(3), "#102030", "#ffffff"},
+		{"Built-in preset", "reddit", Band(13), Zoom(2), "#5f99cf", "#ff4500"},
+		{"Built-in preset ignores case", "Google-Plus", Band(10), Zoom(2), "#dd4b39", "#ffffff"},
+	}
+
+	for _, ot := range optionsTests {
+		t.Run(ot.name, func(t *testing.T) {
+			opts, err := config.Options(ot.preset)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if opts.Band != ot.band || opts.Zoom != ot.zoom || opts.BG.String() != ot.bg || opts.FG.String() != ot.fg {
+				t.Errorf("Expected band %v zoom %v bg %v fg %v, received %v %v %v %v",
+					ot.band, ot.zoom, ot.bg, ot.fg, opts.Band, opts.Zoom, opts.BG.String(), opts.FG.String())
+			}
+			if opts.RateLimit != 250*time.Millisecond {
+				t.Errorf("Expected a rate limit of 250ms, received %v", opts.RateLimit)
+			}
+		})
+	}
+
+	if _, err := config.Options("nonsense"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
+
+// TestLoadConfigDuplicatePresets tests that preset names differing only in
+// case are rejected, as Preset could return either.
+func TestLoadConfigDuplicatePresets(t *testing.T) {
+	fileName := writeConfig(t, `{"presets": {"Night": {"band": "13"}, "night": {"band": "7"}}}`)
+
+	if _, err := LoadConfig(fileName); err == nil {
+		t.Error("Expected an error for presets differing only in case")
+	}
+}
+
+// TestConfigFlagsOverride tests that flags parsed after resolving the
+// config override it.
+func TestConfigFlagsOverride(t *testing.T) {
+	args := []string{"--preset", "arch", "-b", "7"}
+
+	opts, err := (&Config{}).Options(PresetArg(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flags := flag.NewFlagSet("himago", flag.ContinueOnError)
+	flags.String("preset", "", "preset")
+	flags.Var(&opts.Band, "b", "band")
+	flags.Var(&opts.FG, "fg", "foreground")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Band != Band(7) || opts.FG.String() != "#1793d1" {
+		t.Errorf("Expected band 7 and the preset's #1793d1, received %v and %v", opts.Band, opts.FG.String())
+	}
+}
+
+// TestPresetArg tests finding the preset in each form of flag.
+func TestPresetArg(t *testing.T) {
+	presetTests := []struct {
+		name string
+		in   []string
+		out  string
+	}{
+		{"Long flag", []string{"-z", "2", "--preset", "arch"}, "arch"},
+		{"Long flag with equals", []string{"--preset=lego"}, "lego"},
+		{"Short flag", []string{"-preset", "ikea"}, "ikea"},
+		{"No preset", []string{"-o", "preset"}, ""},
+		{"After terminator", []string{"--", "--preset", "arch"}, ""},
+	}
+
+	for _, pt := range presetTests {
+		t.Run(pt.name, func(t *testing.T) {
+			if preset := PresetArg(pt.in); preset != pt.out {
+				t.Errorf("Expected %q, received %q", pt.out, preset)
+			}
+		})
+	}
+}
+
+// TestLoadConfigMissing tests that a missing config file gives an empty
+// Config rather than an error.
+func TestLoadConfigMissing(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil || config == nil {
+		t.Errorf("Expected an empty Config, received %v and %v", config, err)
+	}
+}
+
+// TestConfigPath tests that the config file is found in
+// $XDG_CONFIG_HOME.
+func TestConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := filepath.Join("/tmp/xdg", "himago", "config.json"); path != expected {
+		t.Errorf("Expected %v, received %v", expected, path)
+	}
+}
+
+// TestDefaultOptions tests that the defaults are those in the README.
+func TestDefaultOptions(t *testing.T) {
+	opts := DefaultOptions()
+	if opts.Zoom != Zoom(2) || opts.Output != "output.png" {
+		t.Errorf("Expected zoom 2 written to output.png, received zoom %v written to %v", opts.Zoom, opts.Output)
+	}
+}
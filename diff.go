@@ -0,0 +1,112 @@
+package himago
+
+import (
+	"errors"
+	"image"
+)
+
+// DiffOptions configures Compare.
+type DiffOptions struct {
+	// ColorMap colours the change of each pixel. 0 is the largest decrease
+	// in intensity, 0.5 no change and 1 the largest increase.
+	// Defaults to the Difference colour map.
+	ColorMap ColorMap
+
+	// Threshold is the smallest change in intensity, out of 255, counted
+	// as changed in the DiffSummary.
+	Threshold uint8
+
+	// Region, if set, limits the comparison to an area. Pixels outside it
+	// are transparent in the difference image.
+	Region *Region
+
+	// Provider is the Provider the image came from, which sets its
+	// Projection. If nil, the DefaultFetcher's Provider is assumed.
+	Provider Provider
+}
+
+// DiffSummary summarises the changes between two images.
+type DiffSummary struct {
+	// Pixels is the number of pixels compared.
+	Pixels int `json:"pixels"`
+
+	// ChangedFraction is the fraction of pixels compared whose intensity
+	// changed by more than the Threshold.
+	ChangedFraction float64 `json:"changedFraction"`
+
+	// MeanChange is the mean change in intensity between -1 and 1.
+	// Positive values mean the image became brighter, which for infrared
+	// bands means colder, e.g. developing convection.
+	MeanChange float64 `json:"meanChange"`
+}
+
+// Compare compares two images of the same size pixel by pixel and
+// returns an image of the change coloured by a ColorMap along with a summary.
+// Images should be drawn with a white foreground on a black background,
+// see Stitch.
+func Compare(before, after image.Image, opts DiffOptions) (*image.NRGBA, DiffSummary, error) {
+	var summary DiffSummary
+
+	bounds := before.Bounds()
+	if bounds.Dx() != after.Bounds().Dx() || bounds.Dy() != after.Bounds().Dy() {
+		return nil, summary, errors.New("images must be the same size to compare")
+	}
+
+	colorMap := opts.ColorMap
+	if len(colorMap.Stops) == 0 {
+		colorMap = Difference
+	}
+
+	offset := after.Bounds().Min.Sub(bounds.Min)
+	projection := ProviderProjection(opts.Provider, bounds.Dx())
+
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	changed, total := 0, 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if opts.Region != nil {
+				lat, lon, ok := projection.ToLatLon(float64(x-bounds.Min.X)+0.5, float64(y-bounds.Min.Y)+0.5)
+				if !ok || !opts.Region.Contains(lat, lon) {
+					continue
+				}
+			}
+
+			change := int(pixelValue(after, x+offset.X, y+offset.Y)) - int(pixelValue(before, x, y))
+
+			summary.Pixels++
+			total += change
+			if change > int(opts.Threshold) || -change > int(opts.Threshold) {
+				changed++
+			}
+
+			out.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, colorMap.At((float64(change)/255+1)/2))
+		}
+	}
+
+	if summary.Pixels > 0 {
+		summary.ChangedFraction = float64(changed) / float64(summary.Pixels)
+		summary.MeanChange = float64(total) / float64(summary.Pixels) / 255
+	}
+
+	return out, summary, nil
+}
+
+// Diff downloads the same Band and Zoom at two times and Compares them. The times are rounded and rolled back as in GetCapture.
+func (f *Fetcher) Diff(band Band, zoom Zoom, before, after SatTime, opts DiffOptions) (*image.NRGBA, DiffSummary, error) {
+	first, err := f.GetCapture(band, zoom, before)
+	if err != nil {
+		return nil, DiffSummary{}, err
+	}
+
+	second, err := f.GetCapture(band, zoom, after)
+	if err != nil {
+		return nil, DiffSummary{}, err
+	}
+
+	if opts.Provider == nil {
+		opts.Provider = f.provider()
+	}
+
+	return Compare(stitchIntensity(band, first.Tiles), stitchIntensity(band, second.Tiles), opts)
+}
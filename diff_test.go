@@ -0,0 +1,37 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestCompare tests the summary and colouring of a difference image.
+func TestCompare(t *testing.T) {
+	before := image.NewGray(image.Rect(0, 0, 2, 2))
+	after := image.NewGray(image.Rect(0, 0, 2, 2))
+
+	// One pixel brightens completely, one darkens slightly
+	after.SetGray(0, 0, color.Gray{255})
+	before.SetGray(1, 0, color.Gray{10})
+
+	out, summary, err := Compare(before, after, DiffOptions{Threshold: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Pixels != 4 || summary.ChangedFraction != 0.25 {
+		t.Errorf("Expected 1 of 4 pixels changed, received %+v", summary)
+	}
+
+	if c := out.NRGBAAt(0, 0); c != Difference.At(1) {
+		t.Errorf("Expected the brightened pixel to be %v, received %v", Difference.At(1), c)
+	}
+	if c := out.NRGBAAt(0, 1); c != Difference.At(0.5) {
+		t.Errorf("Expected an unchanged pixel to be %v, received %v", Difference.At(0.5), c)
+	}
+
+	if _, _, err := Compare(before, image.NewGray(image.Rect(0, 0, 3, 3)), DiffOptions{}); err == nil {
+		t.Errorf("Expected an error comparing images of different sizes")
+	}
+}
@@ -0,0 +1,408 @@
+package himago
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Fetcher downloads Tiles over HTTP. The zero value is ready to use and
+// behaves exactly like the package-level functions.
+type Fetcher struct {
+	// Client is used to send requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Cache, if set, stores every response along with its validators.
+	// Repeated requests are sent with If-None-Match/If-Modified-Since and
+	// a 304 Not Modified is served from the cache.
+	Cache *Cache
+
+	// Logger receives structured progress and diagnostic messages.
+	// If nil, nothing is logged.
+	Logger *slog.Logger
+
+	// Progress, if set, is called by GetTiles after every Tile is
+	// downloaded and whenever the image time is rolled back.
+	Progress func(Progress)
+
+	// Metrics, if set, is told about every request, cache lookup and
+	// rollback.
+	Metrics Metrics
+
+	// Provider builds the URLs of images. If nil, Himawari8 is used.
+	Provider Provider
+
+	// Staging, if set, is a directory where the Tiles of a capture are
+	// kept until every Tile has been downloaded. If a capture fails
+	// part way through, trying it again only downloads the missing Tiles.
+	Staging string
+
+	// RateLimit is the minimum time between requests to the server.
+	// Responses served from the Cache are not limited.
+	RateLimit time.Duration
+
+	mu          sync.Mutex
+	lastRequest time.Time
+}
+
+// ErrNoImage is returned by GetExactCapture when the server has no image
+// at the requested time.
+var ErrNoImage = errors.New("no image available at the requested time")
+
+// discardLogger is used when a Fetcher has no Logger, keeping the
+// library silent by default.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// DefaultFetcher is used by GetTiles and the other package-level functions.
+var DefaultFetcher = &Fetcher{}
+
+func (f *Fetcher) client() *http.Client {
+	if f.Client != nil {
+		return f.Client
+	}
+
+	return http.DefaultClient
+}
+
+func (f *Fetcher) metrics() Metrics {
+	if f.Metrics != nil {
+		return f.Metrics
+	}
+
+	return nopMetrics{}
+}
+
+func (f *Fetcher) provider() Provider {
+	if f.Provider != nil {
+		return f.Provider
+	}
+
+	return Himawari8
+}
+
+// wait blocks until RateLimit has passed since the previous request.
+func (f *Fetcher) wait() {
+	if f.RateLimit <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if d := f.RateLimit - time.Since(f.lastRequest); d > 0 {
+		time.Sleep(d)
+	}
+	f.lastRequest = time.Now()
+}
+
+func (f *Fetcher) logger() *slog.Logger {
+	if f.Logger != nil {
+		return f.Logger
+	}
+
+	return discardLogger
+}
+
+// get returns the body of url, consulting the Cache if there is one.
+// Cached responses that are still fresh according to their Cache-Control
+// max-age are returned without contacting the server at all.
+func (f *Fetcher) get(log *slog.Logger, url string) ([]byte, error) {
+	var cached *cacheEntry
+	if f.Cache != nil {
+		var err error
+		cached, err = f.Cache.get(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if cached != nil && cached.fresh(time.Now()) {
+			log.Debug("cache hit", "url", url)
+			f.metrics().CacheHit()
+			return cached.body, nil
+		}
+	}
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil {
+		if cached.ETag != "" {
+			request.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			request.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	f.wait()
+
+	start := time.Now()
+	response, err := f.client().Do(request)
+	if err != nil {
+		f.metrics().ObserveRequest(0, time.Since(start), 0)
+		return nil, err
+	}
+
+	defer func() {
+		err := response.Body.Close()
+		if err != nil {
+			log.Warn("failed to close response body", "url", url, "err", err)
+		}
+	}()
+
+	expires := time.Now().Add(maxAge(response.Header.Get("Cache-Control")))
+
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		// The validators still match, only the freshness has changed
+		cached.Expires = expires
+		log.Debug("cache revalidated", "url", url, "duration", time.Since(start))
+		f.metrics().ObserveRequest(response.StatusCode, time.Since(start), 0)
+		f.metrics().CacheHit()
+		return cached.body, f.Cache.put(cached)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		f.metrics().ObserveRequest(response.StatusCode, time.Since(start), 0)
+		return nil, fmt.Errorf("unexpected status %q for %v", response.Status, url)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	f.metrics().ObserveRequest(response.StatusCode, time.Since(start), len(body))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("downloaded", "url", url, "bytes", len(body), "duration", time.Since(start))
+
+	if f.Cache != nil {
+		f.metrics().CacheMiss()
+
+		err = f.Cache.put(&cacheEntry{
+			URL:          url,
+			ETag:         response.Header.Get("ETag"),
+			LastModified: response.Header.Get("Last-Modified"),
+			Expires:      expires,
+			body:         body,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// downloadTile will send a GET request to url and decode the response into an image
+// using image.Decode.
+// It returns an image.Image and any error encountered.
+// The response body is also returned.
+func (f *Fetcher) downloadTile(log *slog.Logger, url string) (Tile, []byte, error) {
+	var tile Tile
+
+	body, err := f.get(log, url)
+	if err != nil {
+		return tile, nil, err
+	}
+
+	newImg, _, err := image.Decode(bytes.NewReader(body))
+	if err != nil {
+		return tile, body, err
+	}
+
+	// Finally wrap the image.Image in a Tile and return it
+	tile = Tile{newImg}
+	return tile, body, nil
+}
+
+// LatestTime asks the server for the time of the most recent full-disk image.
+// With a Cache the answer is reused for as long as the server's
+// Cache-Control max-age allows.
+func (f *Fetcher) LatestTime() (SatTime, error) {
+	var latest struct {
+		Date string `json:"date"`
+	}
+
+	body, err := f.get(f.logger(), f.provider().LatestURL())
+	if err != nil {
+		return SatTime{}, err
+	}
+
+	if err := json.Unmarshal(body, &latest); err != nil {
+		return SatTime{}, err
+	}
+
+	t, err := time.Parse("2006-01-02 15:04:05", latest.Date)
+	if err != nil {
+		return SatTime{}, err
+	}
+
+	return SatTime{t}, nil
+}
+
+// Capture is a downloaded image: its Tiles along with the parameters that
+// produced them.
+type Capture struct {
+	Band Band
+	Zoom Zoom
+
+	// Time is the time of the image after rounding and any rollbacks,
+	// which may be earlier than the time that was requested.
+	Time SatTime
+
+	Tiles [][]Tile
+
+	// Provider is the Provider the Tiles were downloaded from. If nil,
+	// the DefaultFetcher's Provider is assumed.
+	Provider Provider
+}
+
+// provider returns the Capture's Provider.
+func (c *Capture) provider() Provider {
+	if c.Provider != nil {
+		return c.Provider
+	}
+
+	return DefaultFetcher.provider()
+}
+
+// Projection returns the Projection of the image made by stitching the
+// Capture's Tiles.
+func (c *Capture) Projection() Projection {
+	p := c.provider()
+	return ProviderProjection(p, c.Zoom.GridWidth()*p.TileSize())
+}
+
+// GetTiles retrieves the individual tiles to construct an image at the
+// required zoom level.
+func (f *Fetcher) GetTiles(band Band, zoom Zoom, imageTime SatTime) ([][]Tile, error) {
+	capture, err := f.GetCapture(band, zoom, imageTime)
+	return capture.Tiles, err
+}
+
+// GetCapture retrieves the individual tiles to construct an image at the
+// required zoom level and records the time they were actually taken.
+// On error the Capture holds the Tiles downloaded so far.
+func (f *Fetcher) GetCapture(band Band, zoom Zoom, imageTime SatTime) (*Capture, error) {
+	return f.getCapture(band, zoom, imageTime, 3)
+}
+
+// GetExactCapture is like GetCapture but never rolls back to an earlier
+// time. If there is no image at imageTime it returns ErrNoImage.
+func (f *Fetcher) GetExactCapture(band Band, zoom Zoom, imageTime SatTime) (*Capture, error) {
+	return f.getCapture(band, zoom, imageTime, 0)
+}
+
+// getCapture downloads a capture, rolling back at most rollbacks times.
+func (f *Fetcher) getCapture(band Band, zoom Zoom, imageTime SatTime, rollbacks int) (*Capture, error) {
+	gridWidth := zoom.GridWidth()
+	provider := f.provider()
+	cadence := provider.Cadence()
+
+	tiles := [][]Tile{}
+
+	// Round down to the Provider's cadence, every 10 minutes for Himawari
+	imageTime.RoundTo(cadence)
+
+	if err := supports(provider, band, gridWidth); err != nil {
+		return &Capture{band, zoom, imageTime, tiles, provider}, err
+	}
+
+	log := f.logger().With("band", int(band), "zoom", int(zoom))
+	start := time.Now()
+
+	stage := f.staging(provider, band, imageTime, gridWidth)
+
+	progress := Progress{TilesTotal: gridWidth * gridWidth, Time: imageTime}
+	report := func() {
+		if f.Progress != nil {
+			f.Progress(progress)
+		}
+	}
+
+	// On attempting to download the first tile for an image,
+	// if a "No Image" is detected then roll back one cadence
+	// and try again, up to rollbacks times.
+	firstTile := true
+	remainingRollbacks := rollbacks
+
+	for j := 0; j < gridWidth; j++ {
+		row := []Tile{}
+		for i := 0; i < gridWidth; i++ {
+
+			url := provider.TileURL(band, imageTime, gridWidth, j, i)
+			tileLog := log.With("time", imageTime.Time, "x", j, "y", i)
+			tile, body, err := f.stagedTile(tileLog, stage, url, j, i)
+			progress.Bytes += int64(len(body))
+
+			if err != nil {
+				return &Capture{band, zoom, imageTime, tiles, provider}, &TileError{band, zoom, imageTime, j, i, url, err}
+			}
+
+			// Only perform rollback check on the first tile.
+			// Assumes all tiles to be "No Image" if the first one is.
+			if firstTile {
+				for remainingRollbacks > 0 {
+					if tile.IsNoImage() {
+						log.Info("no image, rolling back", "time", imageTime.Time)
+						imageTime.RollbackBy(cadence)
+						f.metrics().Rollback()
+						progress.Retries++
+						progress.Time = imageTime
+						report()
+
+						// Regenerate the URL will the new time
+						stage = f.staging(provider, band, imageTime, gridWidth)
+						url = provider.TileURL(band, imageTime, gridWidth, j, i)
+						tileLog = log.With("time", imageTime.Time, "x", j, "y", i)
+						tile, body, err = f.stagedTile(tileLog, stage, url, j, i)
+						progress.Bytes += int64(len(body))
+
+						if err != nil {
+							return &Capture{band, zoom, imageTime, tiles, provider}, &TileError{band, zoom, imageTime, j, i, url, err}
+						}
+					}
+					remainingRollbacks--
+				}
+
+				if rollbacks == 0 && tile.IsNoImage() {
+					return &Capture{band, zoom, imageTime, tiles, provider}, ErrNoImage
+				}
+			}
+
+			// Keep the tile in case the capture is interrupted
+			if stage != nil && body != nil {
+				if err := stage.save(j, i, body); err != nil {
+					return &Capture{band, zoom, imageTime, tiles, provider}, err
+				}
+			}
+
+			// Add the tile to the array
+			row = append(row, tile)
+			firstTile = false
+
+			progress.TilesDone++
+			report()
+		}
+		tiles = append(tiles, row)
+	}
+
+	if stage != nil {
+		if err := stage.remove(); err != nil {
+			log.Warn("failed to remove staged tiles", "dir", stage.dir, "err", err)
+		}
+	}
+
+	log.Info("downloaded tiles", "time", imageTime.Time, "tiles", gridWidth*gridWidth, "duration", time.Since(start))
+
+	return &Capture{band, zoom, imageTime, tiles, provider}, nil
+
+}
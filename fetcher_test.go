@@ -0,0 +1,88 @@
+package himago
+
+import (
+	"bytes"
+	"image"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFetcherLogger tests that requests are logged with their URL when a
+// Logger is provided.
+func TestFetcherLogger(t *testing.T) {
+	server, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tile"))
+	})
+
+	var buf bytes.Buffer
+	f.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	if _, err := f.get(f.logger(), server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "url="+server.URL) {
+		t.Errorf("Expected the URL to be logged, received %q", buf.String())
+	}
+}
+
+// countingMetrics records how often each Metrics method is called.
+type countingMetrics struct {
+	requests, hits, misses, rollbacks, stitches int
+}
+
+func (m *countingMetrics) ObserveRequest(int, time.Duration, int) { m.requests++ }
+func (m *countingMetrics) CacheHit()                              { m.hits++ }
+func (m *countingMetrics) CacheMiss()                             { m.misses++ }
+func (m *countingMetrics) Rollback()                              { m.rollbacks++ }
+func (m *countingMetrics) ObserveStitch(time.Duration)            { m.stitches++ }
+
+// TestFetcherMetrics tests that requests and cache lookups are counted.
+func TestFetcherMetrics(t *testing.T) {
+	server, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("tile"))
+	})
+
+	cache, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &countingMetrics{}
+	f.Cache = cache
+	f.Metrics = m
+
+	for i := 0; i < 2; i++ {
+		if _, err := f.get(f.logger(), server.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if m.requests != 1 || m.misses != 1 || m.hits != 1 {
+		t.Errorf("Expected 1 request, 1 miss and 1 hit, received %+v", *m)
+	}
+}
+
+// TestDrawTilesMetrics tests that DrawTiles reports the time taken to
+// stitch to the DefaultFetcher's Metrics.
+func TestDrawTilesMetrics(t *testing.T) {
+	m := &countingMetrics{}
+	defer func(old Metrics) { DefaultFetcher.Metrics = old }(DefaultFetcher.Metrics)
+	DefaultFetcher.Metrics = m
+
+	tiles := [][]Tile{{{image.NewRGBA(image.Rect(0, 0, 4, 4))}}}
+	fileName := filepath.Join(t.TempDir(), "out.png")
+
+	if err := DrawTiles(Band(13), tiles, nil, fileName, Color{}, Color{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.stitches != 1 {
+		t.Errorf("Expected 1 stitch, received %d", m.stitches)
+	}
+}
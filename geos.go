@@ -0,0 +1,121 @@
+package himago
+
+import "math"
+
+// Constants of the geostationary projection used by the Himawari 8 full-disk
+// images, from the JMA Himawari Standard Data User's Guide.
+const (
+	// SubSatelliteLon is the longitude Himawari 8 is stationed above.
+	SubSatelliteLon = 140.7
+
+	// satelliteDistance is the distance from the centre of the Earth to
+	// the satellite in km.
+	satelliteDistance = 42164.0
+
+	equatorialRadius = 6378.137
+	polarRadius      = 6356.7523
+
+	// fullDiskCFAC is the column scaling factor of a 5500 pixel wide
+	// full-disk image, in pixels per degree scaled by 2^16.
+	fullDiskCFAC    = 20466275
+	fullDiskColumns = 5500
+)
+
+// Projection converts between latitude/longitude and pixel coordinates
+// of a square full-disk image, such as one returned by Stitch.
+type Projection struct {
+	// Size is the width and height of the image in pixels.
+	Size int
+
+	// SubLon is the longitude of the sub-satellite point in degrees.
+	SubLon float64
+}
+
+// NewProjection returns the Projection for a Himawari 8 full-disk image
+// size pixels wide.
+func NewProjection(size int) Projection {
+	return Projection{Size: size, SubLon: SubSatelliteLon}
+}
+
+// ProviderProjection returns the Projection for a full-disk image from
+// provider size pixels wide. If provider is nil the DefaultFetcher's
+// Provider is used.
+func ProviderProjection(provider Provider, size int) Projection {
+	if provider == nil {
+		provider = DefaultFetcher.provider()
+	}
+
+	return Projection{Size: size, SubLon: provider.SubSatelliteLon()}
+}
+
+// pixelsPerRadian is the number of pixels per radian of scan angle.
+func (p Projection) pixelsPerRadian() float64 {
+	return fullDiskCFAC / 65536.0 * 180 / math.Pi * float64(p.Size) / fullDiskColumns
+}
+
+// ToPixel returns the pixel coordinates of a latitude and longitude in
+// degrees. ok is false if the point is on the far side of the Earth and
+// cannot be seen by the satellite.
+func (p Projection) ToPixel(lat, lon float64) (x, y float64, ok bool) {
+	latR := lat * math.Pi / 180
+	dLon := (lon - p.SubLon) * math.Pi / 180
+
+	// Geocentric latitude and distance from the centre of the Earth
+	e2 := 1 - (polarRadius*polarRadius)/(equatorialRadius*equatorialRadius)
+	cLat := math.Atan((1 - e2) * math.Tan(latR))
+	rl := polarRadius / math.Sqrt(1-e2*math.Cos(cLat)*math.Cos(cLat))
+
+	r1 := satelliteDistance - rl*math.Cos(cLat)*math.Cos(dLon)
+	r2 := -rl * math.Cos(cLat) * math.Sin(dLon)
+	r3 := rl * math.Sin(cLat)
+	rn := math.Sqrt(r1*r1 + r2*r2 + r3*r3)
+
+	// The point is visible if the satellite is above its horizon
+	if satelliteDistance*rl*math.Cos(cLat)*math.Cos(dLon) < rl*rl {
+		return 0, 0, false
+	}
+
+	scanX := math.Atan(-r2 / r1)
+	scanY := math.Asin(-r3 / rn)
+
+	centre := float64(p.Size) / 2
+	return centre + scanX*p.pixelsPerRadian(), centre + scanY*p.pixelsPerRadian(), true
+}
+
+// ToLatLon returns the latitude and longitude in degrees of the pixel at
+// x, y. ok is false if the pixel is off the edge of the Earth.
+func (p Projection) ToLatLon(x, y float64) (lat, lon float64, ok bool) {
+	centre := float64(p.Size) / 2
+	scanX := (x - centre) / p.pixelsPerRadian()
+	scanY := (y - centre) / p.pixelsPerRadian()
+
+	ratio := (equatorialRadius * equatorialRadius) / (polarRadius * polarRadius)
+	cosX, sinX := math.Cos(scanX), math.Sin(scanX)
+	cosY, sinY := math.Cos(scanY), math.Sin(scanY)
+
+	a := cosY*cosY + ratio*sinY*sinY
+	b := satelliteDistance * cosX * cosY
+	sd := b*b - a*(satelliteDistance*satelliteDistance-equatorialRadius*equatorialRadius)
+	if sd < 0 {
+		return 0, 0, false
+	}
+
+	sn := (b - math.Sqrt(sd)) / a
+	s1 := satelliteDistance - sn*cosX*cosY
+	s2 := sn * sinX * cosY
+	s3 := -sn * sinY
+	sxy := math.Sqrt(s1*s1 + s2*s2)
+
+	lon = math.Atan(s2/s1)*180/math.Pi + p.SubLon
+	lat = math.Atan(ratio*s3/sxy) * 180 / math.Pi
+
+	// Normalise to -180..180
+	lon = math.Mod(lon+540, 360) - 180
+
+	return lat, lon, true
+}
+
+// DiskRadius returns the radius of the Earth's disk in pixels at the equator.
+func (p Projection) DiskRadius() float64 {
+	return math.Asin(equatorialRadius/satelliteDistance) * p.pixelsPerRadian()
+}
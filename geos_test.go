@@ -0,0 +1,69 @@
+package himago
+
+import (
+	"math"
+	"testing"
+)
+
+// TestProjectionRoundTrip tests that converting a point to a pixel and back
+// returns the same point.
+func TestProjectionRoundTrip(t *testing.T) {
+	p := NewProjection(1100)
+
+	points := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"Sub-satellite point", 0, SubSatelliteLon},
+		{"Tokyo", 35.68, 139.69},
+		{"Sydney", -33.87, 151.21},
+		{"Perth", -31.95, 115.86},
+	}
+
+	for _, pt := range points {
+		t.Run(pt.name, func(t *testing.T) {
+			x, y, ok := p.ToPixel(pt.lat, pt.lon)
+			if !ok {
+				t.Fatalf("Expected %v,%v to be visible", pt.lat, pt.lon)
+			}
+
+			lat, lon, ok := p.ToLatLon(x, y)
+			if !ok {
+				t.Fatalf("Expected pixel %v,%v to be on the disk", x, y)
+			}
+
+			if math.Abs(lat-pt.lat) > 1e-6 || math.Abs(lon-pt.lon) > 1e-6 {
+				t.Errorf("Expected %v,%v, received %v,%v", pt.lat, pt.lon, lat, lon)
+			}
+		})
+	}
+}
+
+// TestProjection tests the orientation of the projection and points that
+// cannot be seen.
+func TestProjection(t *testing.T) {
+	p := NewProjection(1100)
+
+	x, y, _ := p.ToPixel(0, SubSatelliteLon)
+	if x != 550 || y != 550 {
+		t.Errorf("Expected the sub-satellite point at the centre, received %v,%v", x, y)
+	}
+
+	// North is up and east is right
+	x, y, _ = p.ToPixel(35.68, 150)
+	if x <= 550 || y >= 550 {
+		t.Errorf("Expected a point north east of the centre, received %v,%v", x, y)
+	}
+
+	if _, _, ok := p.ToPixel(0, SubSatelliteLon-180); ok {
+		t.Errorf("Expected the far side of the Earth to be hidden")
+	}
+
+	if _, _, ok := p.ToLatLon(0, 0); ok {
+		t.Errorf("Expected the corner of the image to be off the disk")
+	}
+
+	if r := p.DiskRadius(); r < 530 || r > 550 {
+		t.Errorf("Expected the disk to nearly fill the image, received radius %v", r)
+	}
+}
@@ -0,0 +1,144 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Viewpoint is the position a globe is seen from.
+type Viewpoint struct {
+	// Lat and Lon of the point directly below the viewer in degrees.
+	Lat float64
+	Lon float64
+
+	// Altitude above the surface in km. 0 gives an orthographic view, as
+	// if seen from infinitely far away.
+	Altitude float64
+}
+
+// GlobeOptions configures RenderGlobe.
+type GlobeOptions struct {
+	// Size is the width and height of the rendered image in pixels.
+	Size int
+
+	View Viewpoint
+
+	// Fill is used for parts of the Earth that cannot be seen by the
+	// satellite. nil leaves them transparent.
+	Fill color.Color
+
+	// Background is used for space around the globe. nil leaves it
+	// transparent.
+	Background color.Color
+
+	// Provider is the Provider the image came from, which sets its
+	// Projection. If nil, the DefaultFetcher's Provider is assumed.
+	Provider Provider
+}
+
+// globeMargin is the fraction of the image the globe fills.
+const globeMargin = 0.95
+
+// vec3 is a vector in an Earth-centred frame with x towards 0°N 0°E,
+// y towards 0°N 90°E and z towards the North Pole, in units of the
+// Earth's radius.
+type vec3 [3]float64
+
+func (a vec3) add(b vec3) vec3      { return vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]} }
+func (a vec3) scale(s float64) vec3 { return vec3{a[0] * s, a[1] * s, a[2] * s} }
+func (a vec3) dot(b vec3) float64   { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func (a vec3) normalise() vec3      { return a.scale(1 / math.Sqrt(a.dot(a))) }
+
+// unitVector points from the centre of the Earth towards lat, lon.
+func unitVector(lat, lon float64) vec3 {
+	lat, lon = lat/degreesPerRadian, lon/degreesPerRadian
+	return vec3{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+// RenderGlobe renders a full-disk image, such as one returned by Stitch,
+// onto a sphere seen from opts.View. Each output pixel is traced back to
+// the Earth and sampled from src using the geostationary Projection.
+func RenderGlobe(src image.Image, opts GlobeOptions) *image.NRGBA {
+	out := image.NewNRGBA(image.Rect(0, 0, opts.Size, opts.Size))
+
+	srcBounds := src.Bounds()
+	projection := ProviderProjection(opts.Provider, srcBounds.Dx())
+
+	// Camera basis: up is north, right is east, forward is towards
+	// the centre of the Earth.
+	lat0, lon0 := opts.View.Lat/degreesPerRadian, opts.View.Lon/degreesPerRadian
+	up := unitVector(opts.View.Lat, opts.View.Lon)
+	east := vec3{-math.Sin(lon0), math.Cos(lon0), 0}
+	north := vec3{-math.Sin(lat0) * math.Cos(lon0), -math.Sin(lat0) * math.Sin(lon0), math.Cos(lat0)}
+
+	distance := 1 + opts.View.Altitude/equatorialRadius
+	camera := up.scale(distance)
+
+	// Scale the field of view so the visible globe fills the image
+	tanHalfAngle := 1 / math.Sqrt(distance*distance-1) / globeMargin
+
+	for py := 0; py < opts.Size; py++ {
+		for px := 0; px < opts.Size; px++ {
+			u := (2*(float64(px)+0.5)/float64(opts.Size) - 1)
+			v := -(2*(float64(py)+0.5)/float64(opts.Size) - 1)
+
+			var point vec3
+			if opts.View.Altitude <= 0 {
+				u, v = u/globeMargin, v/globeMargin
+				depth := 1 - u*u - v*v
+				if depth < 0 {
+					setColor(out, px, py, opts.Background)
+					continue
+				}
+				point = east.scale(u).add(north.scale(v)).add(up.scale(math.Sqrt(depth)))
+			} else {
+				dir := up.scale(-1).add(east.scale(u * tanHalfAngle)).add(north.scale(v * tanHalfAngle)).normalise()
+
+				// Nearest intersection of the ray with the unit sphere
+				b := camera.dot(dir)
+				disc := b*b - (distance*distance - 1)
+				if disc < 0 {
+					setColor(out, px, py, opts.Background)
+					continue
+				}
+				point = camera.add(dir.scale(-b - math.Sqrt(disc)))
+			}
+
+			lat := math.Asin(math.Max(-1, math.Min(1, point[2]))) * degreesPerRadian
+			lon := math.Atan2(point[1], point[0]) * degreesPerRadian
+
+			x, y, ok := projection.ToPixel(lat, lon)
+			if !ok {
+				setColor(out, px, py, opts.Fill)
+				continue
+			}
+
+			out.Set(px, py, src.At(srcBounds.Min.X+int(x), srcBounds.Min.Y+int(y)))
+		}
+	}
+
+	return out
+}
+
+// setColor sets a pixel to c, leaving it transparent if c is nil.
+func setColor(img *image.NRGBA, x, y int, c color.Color) {
+	if c != nil {
+		img.Set(x, y, c)
+	}
+}
+
+// GlobeFrames renders frames images of a globe rotating once eastwards
+// about its axis, as the Earth does, starting from opts.View, for use in an
+// animation. The viewer moves westwards to achieve this.
+func GlobeFrames(src image.Image, opts GlobeOptions, frames int) []*image.NRGBA {
+	images := make([]*image.NRGBA, 0, frames)
+
+	for i := 0; i < frames; i++ {
+		frameOpts := opts
+		frameOpts.View.Lon = math.Mod(opts.View.Lon-360*float64(i)/float64(frames)+540, 360) - 180
+		images = append(images, RenderGlobe(src, frameOpts))
+	}
+
+	return images
+}
@@ -0,0 +1,51 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// TestRenderGlobe tests which parts of the globe are sampled from the
+// satellite image from different viewpoints.
+func TestRenderGlobe(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	grey := color.NRGBA{128, 128, 128, 255}
+
+	src := image.NewNRGBA(image.Rect(0, 0, 200, 200))
+	draw.Draw(src, src.Bounds(), image.NewUniform(red), image.ZP, draw.Src)
+
+	globeTests := []struct {
+		name   string
+		view   Viewpoint
+		centre color.NRGBA
+	}{
+		{"Orthographic above the satellite", Viewpoint{0, SubSatelliteLon, 0}, red},
+		{"Perspective above the satellite", Viewpoint{0, SubSatelliteLon, 35786}, red},
+		{"Orthographic from the far side", Viewpoint{0, SubSatelliteLon - 180, 0}, grey},
+	}
+
+	for _, gt := range globeTests {
+		t.Run(gt.name, func(t *testing.T) {
+			out := RenderGlobe(src, GlobeOptions{Size: 50, View: gt.view, Fill: grey})
+
+			if c := out.NRGBAAt(25, 25); c != gt.centre {
+				t.Errorf("Expected %v at the centre, received %v", gt.centre, c)
+			}
+			if c := out.NRGBAAt(0, 0); c.A != 0 {
+				t.Errorf("Expected space to be transparent, received %v", c)
+			}
+		})
+	}
+}
+
+// TestGlobeFrames tests that one frame is rendered per step.
+func TestGlobeFrames(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+
+	frames := GlobeFrames(src, GlobeOptions{Size: 10}, 4)
+	if len(frames) != 4 {
+		t.Errorf("Expected 4 frames, received %v", len(frames))
+	}
+}
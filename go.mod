@@ -1,5 +1,5 @@
 module github.com/tscott0/himago
 
-go 1.12
+go 1.24
 
 require github.com/ogier/pflag v0.0.1 // indirect
@@ -0,0 +1,58 @@
+package himago
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// redirectTransport sends every request to a test server instead.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (rt redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme = rt.target.Scheme
+	r.URL.Host = rt.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// blankTile returns a PNG of a black Tile size pixels wide.
+func blankTile(t *testing.T, size int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, size, size))); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// newTestServer starts a server which answers requests with handler and
+// is closed when the test finishes. The Fetcher returned sends every
+// request to the server, whatever its URL.
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *Fetcher) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	f := &Fetcher{Client: &http.Client{Transport: redirectTransport{target}}}
+
+	return server, f
+}
+
+// newTileServer starts a server which answers every request with a blank
+// Tile and returns a Fetcher that talks to it.
+func newTileServer(t *testing.T) *Fetcher {
+	tile := blankTile(t, defaultTileSize)
+
+	_, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tile)
+	})
+
+	return f
+}
@@ -4,169 +4,128 @@
 package himago
 
 import (
-	"fmt"
 	"image"
 	"image/draw"
-	"image/png"
-	"net/http"
 	"os"
+	"time"
 )
 
 const defaultTileSize = 550
 
-// downloadTile will send a GET request to url and decode the response into an image
-// using image.Decode.
-// It returns an image.Image and any error encountered.
-func downloadTile(url string) (Tile, error) {
-	fmt.Printf("Downloading %v\n", url)
-	var tile Tile
-
-	response, err := http.Get(url)
-	if err != nil {
-		return tile, err
-	}
-
-	defer func() {
-		err := response.Body.Close()
-		if err != nil {
-			fmt.Println("Failed to close response body")
-		}
-	}()
-
-	newImg, _, err := image.Decode(response.Body)
-	if err != nil {
-		return tile, err
-	}
-
-	// Finally wrap the image.Image in a Tile and return it
-	tile = Tile{newImg}
-	return tile, nil
-}
-
-// Take a SatTime and construct a URL.
-// Assumes that the time is valid.
-func urlFromSatTime(band Band, t SatTime, gridWidth, i, j int) string {
-	return fmt.Sprintf(band.URL(),
-		gridWidth,
-		t.Year(),
-		int(t.Month()),
-		t.Day(),
-		t.Hour(),
-		t.Minute(),
-		j,
-		i)
-}
-
 // GetTiles retrieves the individual tiles to construct an image at the
-// required zoom level.
+// required zoom level using the DefaultFetcher.
 func GetTiles(band Band, zoom Zoom, imageTime SatTime) ([][]Tile, error) {
-	gridWidth := zoom.GridWidth()
-
-	tiles := [][]Tile{}
-
-	// Round down to the nearest 10 minutes
-	imageTime.Round()
-
-	// On attempting to download the first tile for an image,
-	// if a "No Image" is detected then roll back 10 minutes
-	// and try again. Try 3 times and then error.
-	firstTile := true
-	remainingRollbacks := 3
-
-	for j := 0; j < gridWidth; j++ {
-		row := []Tile{}
-		for i := 0; i < gridWidth; i++ {
-
-			url := urlFromSatTime(band, imageTime, gridWidth, i, j)
-			tile, err := downloadTile(url)
-
-			if err != nil {
-				return tiles, err
-			}
-
-			// Only perform rollback check on the first tile.
-			// Assumes all tiles to be "No Image" if the first one is.
-			if firstTile {
-				for remainingRollbacks > 0 {
-					if tile.IsNoImage() {
-						fmt.Println("Bad image, rolling back.")
-						imageTime.Rollback()
-
-						// Regenerate the URL will the new time
-						url = urlFromSatTime(band, imageTime, gridWidth, i, j)
-						tile, err = downloadTile(url)
-
-						if err != nil {
-							return tiles, err
-						}
-					}
-					remainingRollbacks--
-				}
-			}
-
-			// Add the tile to the array
-			row = append(row, tile)
-			firstTile = false
-		}
-		tiles = append(tiles, row)
-	}
-
-	return tiles, nil
-
+	return DefaultFetcher.GetTiles(band, zoom, imageTime)
 }
 
-// DrawTiles takes a collection of Tiles and writes them to file.
-func DrawTiles(band Band, tiles [][]Tile, outImg draw.Image, fileName string, bg Color, fg Color) error {
-	// Set the background colour
-	backdrop := image.NewUniform(bg)
+// Stitch draws a collection of Tiles onto a single image over a backdrop of
+// bg. When using a band the Tiles are recoloured to fg, scaling their
+// transparency by the alpha of fg. The zero value Color is transparent
+// black, so a band stitched with it shows only bg; use an opaque colour
+// such as white instead. The Tiles themselves are not modified.
+func Stitch(band Band, tiles [][]Tile, bg Color, fg Color) *image.RGBA {
+	return StitchBackground(band, tiles, bg, fg)
+}
 
-	outFile, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
+// StitchBackground is like Stitch but draws the Tiles over any
+// Background, such as a LinearGradient or an ImageBackground. A nil
+// Background is drawn as opaque black.
+func StitchBackground(band Band, tiles [][]Tile, bg Background, fg Color) *image.RGBA {
+	bg = orBlack(bg)
 
 	// Assume images are always square
 	gridWidth := len(tiles)
 
+	// Providers may use a different tile size, so take it from the Tiles
+	tileSize := defaultTileSize
+	if gridWidth > 0 && len(tiles[0]) > 0 && tiles[0][0].Image != nil {
+		tileSize = tiles[0][0].Bounds().Dx()
+	}
+
 	// Create a new image with a black background
-	imgRect := image.Rect(0, 0, gridWidth*defaultTileSize, gridWidth*defaultTileSize)
-	outImg = image.NewRGBA(imgRect)
+	imgRect := image.Rect(0, 0, gridWidth*tileSize, gridWidth*tileSize)
+	outImg := image.NewRGBA(imgRect)
 
-	draw.Draw(outImg, outImg.Bounds(), backdrop, image.ZP, draw.Src)
+	draw.Draw(outImg, outImg.Bounds(), bg.Render(outImg.Bounds()), image.ZP, draw.Src)
 
 	// Loop over the Tiles and Draw them
 	for x := 0; x < gridWidth; x++ {
 		for y := 0; y < gridWidth; y++ {
 			// Define the bounds of the image.Rectangle for this Tile
 			tileRect := image.Rect(
-				x*defaultTileSize,
-				y*defaultTileSize,
-				(x+1)*defaultTileSize,
-				(y+1)*defaultTileSize)
+				x*tileSize,
+				y*tileSize,
+				(x+1)*tileSize,
+				(y+1)*tileSize)
 
 			// Full colour images have no transparency
 			// Only set the foreground colour when using a band
+			tile := tiles[x][y]
 			if band != Band(0) {
-				tiles[x][y].setForeground(fg)
+				tile = tile.withForeground(fg)
 			}
 
 			// Draw the Tile to the Image
 			draw.Draw(outImg,
 				tileRect,
-				image.Image(tiles[x][y]),
+				image.Image(tile),
 				image.ZP,
 				draw.Over)
 		}
 	}
 
-	// Write the image to file
-	//err = jpeg.Encode(outFile, outImg, &jpeg.Options{90})
-	err = png.Encode(outFile, outImg)
-	if err != nil {
+	return outImg
+}
+
+// DrawTiles takes a collection of Tiles, stitches them over bg and writes
+// them to file.
+// A fileName of "-" writes the image to standard output.
+// The time of the Tiles is unknown so it is left out of the embedded Metadata,
+// use DrawCapture to include it.
+func DrawTiles(band Band, tiles [][]Tile, outImg draw.Image, fileName string, bg Background, fg Color) error {
+	start := time.Now()
+	outImg = StitchBackground(band, tiles, bg, fg)
+	DefaultFetcher.metrics().ObserveStitch(time.Since(start))
+
+	meta := NewMetadata(&Capture{Band: band, Zoom: zoomForGridWidth(len(tiles))}, bg, fg)
+
+	if err := WritePNG(fileName, outImg, meta); err != nil {
 		return err
 	}
 
-	fmt.Printf("\nSaved to %v\n", fileName)
+	DefaultFetcher.logger().Info("saved", "file", fileName)
+	return nil
+}
+
+// DrawCapture stitches the Tiles of a Capture and writes them to file with
+// Metadata describing the capture embedded in the PNG.
+// A fileName of "-" writes the image to standard output.
+func DrawCapture(c *Capture, fileName string, bg Background, fg Color) error {
+	start := time.Now()
+	img := StitchBackground(c.Band, c.Tiles, bg, fg)
+	DefaultFetcher.metrics().ObserveStitch(time.Since(start))
+
+	if err := WritePNG(fileName, img, NewMetadata(c, bg, fg)); err != nil {
+		return err
+	}
 
+	DefaultFetcher.logger().Info("saved", "file", fileName)
 	return nil
 }
+
+// WritePNG encodes img as a PNG with meta embedded in text chunks and
+// writes it to fileName. A fileName of "-" writes to standard output.
+// meta may be nil.
+func WritePNG(fileName string, img image.Image, meta *Metadata) error {
+	outFile := os.Stdout
+	if fileName != "-" {
+		var err error
+		outFile, err = os.Create(fileName)
+		if err != nil {
+			return err
+		}
+		defer outFile.Close()
+	}
+
+	return encodePNG(outFile, img, meta)
+}
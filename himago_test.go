@@ -1,11 +1,120 @@
-package himago
+package himago_test
 
-import "testing"
+import (
+	"bytes"
+	"image/png"
+	"net/http"
+	"testing"
+	"time"
 
-// Download a single tile that exists
+	"github.com/tscott0/himago"
+	"github.com/tscott0/himago/himagotest"
+)
+
+// TestDownloadSingleTile downloads a single Tile from a fake server.
 func TestDownloadSingleTile(t *testing.T) {
-	_, err := downloadTile("http://himawari8-dl.nict.go.jp/himawari8/img/D531106/2d/550/2017/02/03/191000_1_0.png")
+	server := himagotest.NewServer()
+	defer server.Close()
+
+	tiles, err := server.Fetcher().GetTiles(himago.Band(0), himago.Zoom(1), himago.SatTime{Time: time.Date(2017, 2, 3, 19, 10, 0, 0, time.UTC)})
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+
+	if width := tiles[0][0].Bounds().Dx(); width != himagotest.TileSize {
+		t.Errorf("Expected a tile width of %v, received %v", himagotest.TileSize, width)
+	}
+
+	expected := "/D531106/1d/550/2017/02/03/191000_0_0.png"
+	if request := server.Requests()[0]; request != expected {
+		t.Errorf("Expected a request for %v, received %v", expected, request)
+	}
+}
+
+// TestGetCaptureFake runs the download pipeline against a fake server.
+func TestGetCaptureFake(t *testing.T) {
+	requested := himago.SatTime{Time: time.Date(2017, 4, 29, 15, 40, 0, 0, time.UTC)}
+
+	captureTests := []struct {
+		name  string
+		setup func(*himagotest.Server)
+		time  string
+		fails bool
+	}{
+		{"Available", func(s *himagotest.Server) {}, "15:40", false},
+		{"No Image rolls back", func(s *himagotest.Server) {
+			s.SetNoImage(requested, himago.SatTime{Time: requested.Add(-10 * time.Minute)})
+		}, "15:20", false},
+		{"Server error", func(s *himagotest.Server) {
+			s.FailNext(1, http.StatusServiceUnavailable)
+		}, "15:40", true},
+		{"Latency", func(s *himagotest.Server) {
+			s.SetLatency(time.Millisecond)
+		}, "15:40", false},
+	}
+
+	for _, ct := range captureTests {
+		t.Run(ct.name, func(t *testing.T) {
+			server := himagotest.NewServer()
+			defer server.Close()
+			ct.setup(server)
+
+			capture, err := server.Fetcher().GetCapture(himago.Band(13), himago.Zoom(2), requested)
+			if ct.fails && err == nil {
+				t.Fatal("Expected an error, received none")
+			}
+			if !ct.fails && err != nil {
+				t.Fatalf("Expected no error, received %v", err)
+			}
+
+			if captureTime := capture.Time.Format("15:04"); captureTime != ct.time {
+				t.Errorf("Expected a capture at %v, received %v", ct.time, captureTime)
+			}
+
+			if ct.fails {
+				return
+			}
+
+			// Tiles are indexed by column then row
+			var white himago.Color
+			if err := white.Set("white"); err != nil {
+				t.Fatal(err)
+			}
+			img := himago.Stitch(capture.Band, capture.Tiles, himago.Color{}, white)
+			if _, _, _, a := img.At(himagotest.TileSize+1, 1).RGBA(); a>>8 != 16 {
+				t.Errorf("Expected an alpha of 16 in tile 1, 0, received %v", a>>8)
+			}
+		})
+	}
+}
+
+// TestLatestTimeFake tests LatestTime against a fake server.
+func TestLatestTimeFake(t *testing.T) {
+	server := himagotest.NewServer()
+	defer server.Close()
+
+	expected := himago.SatTime{Time: time.Date(2019, 9, 1, 3, 20, 0, 0, time.UTC)}
+	server.SetLatest(expected)
+
+	latest, err := server.Fetcher().LatestTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !latest.Equal(expected.Time) {
+		t.Errorf("Expected %v, received %v", expected, latest)
+	}
+}
+
+// TestNoImagePNG tests that the fake server's "No Image" placeholder is
+// detected as one.
+func TestNoImagePNG(t *testing.T) {
+	img, err := png.Decode(bytes.NewReader(himagotest.NoImagePNG))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tile := himago.Tile{Image: img}
+	if !tile.IsNoImage() {
+		t.Errorf("Failed to detect \"No Image\"")
 	}
 }
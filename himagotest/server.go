@@ -0,0 +1,218 @@
+// Package himagotest provides a fake Himawari tile server, so that the
+// whole download pipeline can be tested hermetically or developed against
+// offline.
+//
+//	server := himagotest.NewServer()
+//	defer server.Close()
+//
+//	fetcher := server.Fetcher()
+//	capture, err := fetcher.GetCapture(himago.Band(13), himago.Zoom(2), t)
+//
+// The server mimics the layout of Band.URL, so any Fetcher using the
+// Provider returned by Server.Provider can talk to it.
+package himagotest
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tscott0/himago"
+)
+
+// TileSize is the width and height of every generated Tile.
+const TileSize = 550
+
+// NoImagePNG is the "No Image" placeholder served by NICT in place of
+// missing images. The himago package's own tests read the same file.
+//
+//go:embed noimage.png
+var NoImagePNG []byte
+
+// TileFunc generates the Tile in column x and row y of a gridWidth x
+// gridWidth image of band taken at t.
+type TileFunc func(band himago.Band, t himago.SatTime, gridWidth, x, y int) image.Image
+
+// tilePath matches the path of a Tile URL built by himago.Himawari.
+var tilePath = regexp.MustCompile(`^/(?:D531106|FULL_24h/B(\d\d))/(\d+)d/550/(\d{4})/(\d\d)/(\d\d)/(\d\d)(\d\d)00_(\d+)_(\d+)\.png$`)
+
+// Server is a fake tile server. It is safe to change its behaviour with
+// the Set methods while requests are in flight.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	tile       TileFunc
+	latest     time.Time
+	noImage    map[time.Time]bool
+	latency    time.Duration
+	failures   int
+	failStatus int
+	requests   []string
+}
+
+// NewServer starts a Server which serves tiles from DefaultTile.
+// The latest image is at the start of the current 10 minutes.
+// It should be closed when finished with.
+func NewServer() *Server {
+	s := &Server{
+		tile:    DefaultTile,
+		latest:  time.Now().UTC().Truncate(10 * time.Minute),
+		noImage: map[time.Time]bool{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Provider returns a himago.Provider that builds URLs on this Server.
+func (s *Server) Provider() *himago.Himawari {
+	return &himago.Himawari{Satellite: "Himawari-8 (fake)", BaseURL: s.URL + "/"}
+}
+
+// Fetcher returns a himago.Fetcher that downloads from this Server.
+func (s *Server) Fetcher() *himago.Fetcher {
+	return &himago.Fetcher{Client: s.Client(), Provider: s.Provider()}
+}
+
+// SetTile replaces the function used to generate Tiles.
+func (s *Server) SetTile(fn TileFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tile = fn
+}
+
+// SetLatest sets the time reported by latest.json.
+func (s *Server) SetLatest(t himago.SatTime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest = t.UTC()
+}
+
+// SetNoImage makes every Tile at the given times the "No Image" placeholder.
+func (s *Server) SetNoImage(times ...himago.SatTime) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range times {
+		s.noImage[t.UTC()] = true
+	}
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext answers the next n requests with status, such as
+// http.StatusServiceUnavailable.
+func (s *Server) FailNext(n int, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failStatus = status
+}
+
+// Requests returns the path of every request received so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	latency := s.latency
+	fail := s.failures > 0
+	if fail {
+		s.failures--
+	}
+	status := s.failStatus
+	tile := s.tile
+	latest := s.latest
+	s.mu.Unlock()
+
+	time.Sleep(latency)
+
+	if fail {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	if r.URL.Path == "/D531106/latest.json" {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"date":"%v","file":"PI_H08_%v_R21_FLDK.png"}`,
+			latest.Format("2006-01-02 15:04:05"), latest.Format("20060102_1504"))
+		return
+	}
+
+	m := tilePath.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Every submatch other than the band is a validated number
+	n := make([]int, len(m))
+	for i := 2; i < len(m); i++ {
+		n[i], _ = strconv.Atoi(m[i])
+	}
+	band := 0
+	if m[1] != "" {
+		band, _ = strconv.Atoi(m[1])
+	}
+
+	gridWidth, x, y := n[2], n[8], n[9]
+	t := time.Date(n[3], time.Month(n[4]), n[5], n[6], n[7], 0, 0, time.UTC)
+
+	if band > 16 || x >= gridWidth || y >= gridWidth {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+
+	s.mu.Lock()
+	missing := s.noImage[t]
+	s.mu.Unlock()
+
+	if missing {
+		w.Write(NoImagePNG)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, tile(himago.Band(band), himago.SatTime{Time: t}, gridWidth, x, y)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf.Bytes())
+}
+
+// DefaultTile generates a flat Tile whose colour identifies it: red is the
+// column, green is the row and blue is the band. Band tiles are greyscale
+// with alpha, as the real ones are, with the column and row in the grey.
+func DefaultTile(band himago.Band, t himago.SatTime, gridWidth, x, y int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, TileSize, TileSize))
+
+	c := color.NRGBA{uint8(x * 16), uint8(y * 16), uint8(band * 15), 255}
+	if band != 0 {
+		c = color.NRGBA{255, 255, 255, uint8(x*16 + y)}
+	}
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+
+	return img
+}
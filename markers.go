@@ -0,0 +1,336 @@
+package himago
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MarkerStyle describes how a Marker is drawn.
+// Zero fields are taken from the default style passed to LoadMarkers.
+type MarkerStyle struct {
+	Color color.Color
+
+	// Radius of the marker in pixels.
+	Radius float64
+
+	// Shape is "circle", "square" or "cross".
+	Shape string
+}
+
+// Marker is a labelled point drawn by DrawMarkers.
+type Marker struct {
+	LatLon
+	Label string
+	Style MarkerStyle
+}
+
+// LoadMarkers loads points from a CSV (.csv) or GeoJSON (.geojson or .json)
+// file. Styles missing from the file are taken from def.
+//
+// CSV files either have the columns lat, lon and label in that order, or a
+// header row naming any of lat, lon, label, color, radius and shape.
+// GeoJSON Points use the properties "label" (or "name"), "color" (or
+// "marker-color"), "radius" and "shape".
+func LoadMarkers(fileName string, def MarkerStyle) ([]Marker, error) {
+	var markers []Marker
+	var err error
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		var f *os.File
+		f, err = os.Open(fileName)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		markers, err = readMarkersCSV(f)
+	case ".geojson", ".json":
+		var data []byte
+		data, err = os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		markers, err = parseGeoJSONMarkers(data)
+	default:
+		return nil, fmt.Errorf("unsupported marker file %v, expected .csv or .geojson", fileName)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range markers {
+		markers[i].Style = markers[i].Style.withDefaults(def)
+	}
+
+	return markers, nil
+}
+
+// withDefaults fills any zero fields of s from def.
+func (s MarkerStyle) withDefaults(def MarkerStyle) MarkerStyle {
+	if s.Color == nil {
+		s.Color = def.Color
+	}
+	if s.Radius == 0 {
+		s.Radius = def.Radius
+	}
+	if s.Shape == "" {
+		s.Shape = def.Shape
+	}
+	return s
+}
+
+// parseStyle builds a MarkerStyle from optional text fields.
+func parseStyle(colour, radius, shape string) (MarkerStyle, error) {
+	var style MarkerStyle
+
+	if colour != "" {
+		var c Color
+		if err := c.Set(colour); err != nil {
+			return style, err
+		}
+		style.Color = c
+	}
+
+	if radius != "" {
+		r, err := strconv.ParseFloat(radius, 64)
+		if err != nil {
+			return style, fmt.Errorf("invalid marker radius %q", radius)
+		}
+		style.Radius = r
+	}
+
+	style.Shape = strings.ToLower(shape)
+	return style, nil
+}
+
+// readMarkersCSV reads markers from CSV, see LoadMarkers.
+func readMarkersCSV(r io.Reader) ([]Marker, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{"lat": 0, "lon": 1, "label": 2}
+
+	// A header row is detected by a non-numeric latitude
+	if len(records) > 0 && len(records[0]) > 0 {
+		if _, err := strconv.ParseFloat(records[0][0], 64); err != nil {
+			columns = map[string]int{}
+			for i, name := range records[0] {
+				switch strings.ToLower(name) {
+				case "lat", "latitude":
+					columns["lat"] = i
+				case "lon", "lng", "longitude":
+					columns["lon"] = i
+				case "label", "name":
+					columns["label"] = i
+				case "color", "colour":
+					columns["color"] = i
+				case "radius", "size":
+					columns["radius"] = i
+				case "shape":
+					columns["shape"] = i
+				}
+			}
+			records = records[1:]
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var markers []Marker
+	for line, record := range records {
+		lat, err := strconv.ParseFloat(field(record, "lat"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude on row %v", line+1)
+		}
+		lon, err := strconv.ParseFloat(field(record, "lon"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude on row %v", line+1)
+		}
+
+		style, err := parseStyle(field(record, "color"), field(record, "radius"), field(record, "shape"))
+		if err != nil {
+			return nil, fmt.Errorf("%v on row %v", err, line+1)
+		}
+
+		markers = append(markers, Marker{LatLon{lat, lon}, field(record, "label"), style})
+	}
+
+	return markers, nil
+}
+
+// markerProperties are the GeoJSON properties used by markers.
+type markerProperties struct {
+	Label       string          `json:"label"`
+	Name        string          `json:"name"`
+	Color       string          `json:"color"`
+	MarkerColor string          `json:"marker-color"`
+	Radius      json.RawMessage `json:"radius"`
+	Shape       string          `json:"shape"`
+}
+
+// parseGeoJSONMarkers returns a Marker for every Point in a GeoJSON
+// document, see LoadMarkers.
+func parseGeoJSONMarkers(data []byte) ([]Marker, error) {
+	var obj geoJSON
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	var markers []Marker
+	err := obj.walk(func(g *geoJSON) error {
+		var points [][]float64
+		switch g.Type {
+		case "Point":
+			var point []float64
+			if err := json.Unmarshal(g.Coordinates, &point); err != nil {
+				return err
+			}
+			points = append(points, point)
+		case "MultiPoint":
+			if err := json.Unmarshal(g.Coordinates, &points); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+
+		var props markerProperties
+		if len(g.Properties) > 0 {
+			if err := json.Unmarshal(g.Properties, &props); err != nil {
+				return err
+			}
+		}
+
+		label := props.Label
+		if label == "" {
+			label = props.Name
+		}
+
+		colour := props.Color
+		if colour == "" {
+			colour = props.MarkerColor
+			// simplestyle colours may omit the #
+			if _, err := strconv.ParseUint(colour, 16, 32); err == nil {
+				colour = "#" + colour
+			}
+		}
+
+		style, err := parseStyle(colour, strings.Trim(string(props.Radius), `"`), props.Shape)
+		if err != nil {
+			return err
+		}
+
+		for _, position := range pathFromCoordinates(points) {
+			markers = append(markers, Marker{position, label, style})
+		}
+		return nil
+	})
+
+	return markers, err
+}
+
+// MarkerOptions configures DrawMarkers.
+type MarkerOptions struct {
+	// Font for labels, defaults to BasicFont with a Scale of 2.
+	Font Font
+
+	// LabelColor defaults to the colour of each marker.
+	LabelColor color.Color
+
+	// LabelBackground, if not nil, is drawn behind labels.
+	LabelBackground color.Color
+
+	// Provider is the Provider the image came from, which sets its
+	// Projection. If nil, the DefaultFetcher's Provider is assumed.
+	Provider Provider
+}
+
+// DrawMarkers draws each Marker and its label onto a full-disk image.
+// Markers on the far side of the Earth are skipped.
+func DrawMarkers(dst draw.Image, markers []Marker, opts MarkerOptions) {
+	bounds := dst.Bounds()
+	projection := ProviderProjection(opts.Provider, bounds.Dx())
+
+	font := opts.Font
+	if font == nil {
+		font = BasicFont{Scale: 2}
+	}
+
+	for _, m := range markers {
+		x, y, ok := projection.ToPixel(m.Lat, m.Lon)
+		if !ok {
+			continue
+		}
+		x += float64(bounds.Min.X)
+		y += float64(bounds.Min.Y)
+
+		radius := m.Style.Radius
+		if radius <= 0 {
+			radius = 4
+		}
+
+		c := m.Style.Color
+		if c == nil {
+			c = color.White
+		}
+
+		drawMarker(dst, x, y, radius, m.Style.Shape, c)
+
+		if m.Label == "" {
+			continue
+		}
+
+		labelColor := opts.LabelColor
+		if labelColor == nil {
+			labelColor = c
+		}
+
+		// Labels sit to the right of the marker, vertically centred
+		origin := image.Pt(int(x+radius+3), int(y)-font.LineHeight()/2)
+		drawTextAt(dst, origin, m.Label, font, labelColor, opts.LabelBackground)
+	}
+}
+
+// drawMarker draws a single marker centred on x, y.
+func drawMarker(dst draw.Image, x, y, radius float64, shape string, c color.Color) {
+	extent := int(math.Ceil(radius)) + 2
+	area := image.Rect(int(x)-extent, int(y)-extent, int(x)+extent+1, int(y)+extent+1).Intersect(dst.Bounds())
+	mask := image.NewAlpha(area)
+
+	switch shape {
+	case "square":
+		rect := image.Rect(int(x-radius), int(y-radius), int(math.Ceil(x+radius)), int(math.Ceil(y+radius)))
+		draw.Draw(mask, rect, image.Opaque, image.ZP, draw.Src)
+	case "cross":
+		width := math.Max(2, radius/2)
+		strokeSegment(mask, x-radius, y-radius, x+radius, y+radius, width)
+		strokeSegment(mask, x-radius, y+radius, x+radius, y-radius, width)
+	default:
+		// A zero length segment is a filled circle
+		strokeSegment(mask, x, y, x, y, radius*2)
+	}
+
+	draw.DrawMask(dst, area, image.NewUniform(c), image.ZP, mask, area.Min, draw.Over)
+}
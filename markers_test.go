@@ -0,0 +1,91 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// TestReadMarkersCSV tests CSV files with and without a header row.
+func TestReadMarkersCSV(t *testing.T) {
+	markers, err := readMarkersCSV(strings.NewReader("35.68,139.69,Tokyo\n-33.87,151.21,Sydney\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(markers) != 2 || markers[1].Label != "Sydney" || markers[1].Lat != -33.87 {
+		t.Errorf("Unexpected markers %+v", markers)
+	}
+
+	markers, err = readMarkersCSV(strings.NewReader("name,lon,lat,colour\nPerth,115.86,-31.95,#ff0000\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(markers) != 1 || markers[0].Lon != 115.86 || markers[0].Label != "Perth" {
+		t.Fatalf("Unexpected markers %+v", markers)
+	}
+	if r, _, _, _ := markers[0].Style.Color.RGBA(); r != 0xffff {
+		t.Errorf("Expected a red marker, received %v", markers[0].Style.Color)
+	}
+
+	if _, err := readMarkersCSV(strings.NewReader("35.68,east,Tokyo\n")); err == nil {
+		t.Errorf("Expected an error for an invalid longitude")
+	}
+}
+
+// TestParseGeoJSONMarkers tests labels and styles of GeoJSON Points.
+func TestParseGeoJSONMarkers(t *testing.T) {
+	markers, err := parseGeoJSONMarkers([]byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "properties": {"name": "Tokyo", "marker-color": "00ff00", "radius": 6},
+			 "geometry": {"type": "Point", "coordinates": [139.69, 35.68]}},
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[140, 35], [141, 36]]}}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(markers) != 1 || markers[0].Label != "Tokyo" || markers[0].Style.Radius != 6 {
+		t.Fatalf("Unexpected markers %+v", markers)
+	}
+	if markers[0].LatLon != (LatLon{35.68, 139.69}) {
+		t.Errorf("Expected 35.68,139.69, received %v", markers[0].LatLon)
+	}
+}
+
+// TestDrawMarkers tests that visible markers are drawn and hidden ones are
+// skipped.
+func TestDrawMarkers(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	red := color.RGBA{255, 0, 0, 255}
+
+	markers := []Marker{
+		{LatLon{0, SubSatelliteLon}, "", MarkerStyle{Color: red, Radius: 3}},
+		{LatLon{0, SubSatelliteLon - 180}, "Hidden", MarkerStyle{Color: red, Radius: 3}},
+	}
+	DrawMarkers(img, markers, MarkerOptions{})
+
+	if img.RGBAAt(50, 50) != red {
+		t.Errorf("Expected a marker at the centre")
+	}
+	if n := countColor(img, red); n > 50 {
+		t.Errorf("Expected only one small marker, received %v red pixels", n)
+	}
+}
+
+// TestDrawMarkersProvider tests that markers are placed using the
+// Projection of the Provider in MarkerOptions.
+func TestDrawMarkersProvider(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	red := color.RGBA{255, 0, 0, 255}
+
+	provider := fakeProvider{}
+	markers := []Marker{{LatLon{0, provider.SubSatelliteLon()}, "", MarkerStyle{Color: red, Radius: 3}}}
+	DrawMarkers(img, markers, MarkerOptions{Provider: provider})
+
+	if img.RGBAAt(50, 50) != red {
+		t.Errorf("Expected a marker at the centre")
+	}
+}
@@ -0,0 +1,71 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DiskMaskOptions configures MaskDisk.
+type DiskMaskOptions struct {
+	// Feather is the width in pixels over which the edge of the disk fades
+	// to transparent. The edge is always anti-aliased over one pixel.
+	Feather float64
+
+	// Glow, if not nil, is drawn outside the limb fading out over
+	// GlowWidth pixels, like an atmosphere.
+	Glow      color.Color
+	GlowWidth float64
+}
+
+// limbRadii returns the horizontal and vertical radii of the Earth's disk
+// in pixels. The Earth is slightly oblate so the disk is an ellipse.
+func (p Projection) limbRadii() (rx, ry float64) {
+	return p.DiskRadius(), math.Asin(polarRadius/satelliteDistance) * p.pixelsPerRadian()
+}
+
+// MaskDisk returns a copy of a full-disk image with everything outside the
+// Earth's limb made transparent, so that it can be composited over other
+// backgrounds. The limb is computed from the geostationary geometry rather
+// than the image content, so it works for black true-colour backgrounds and
+// coloured band backgrounds alike.
+func MaskDisk(img image.Image, opts DiskMaskOptions) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	// The limb does not depend on the sub-satellite longitude
+	rx, ry := Projection{Size: bounds.Dx()}.limbRadii()
+	centre := float64(bounds.Dx()) / 2
+	feather := math.Max(opts.Feather, 1)
+
+	var glow color.NRGBA
+	if opts.Glow != nil {
+		glow = color.NRGBAModel.Convert(opts.Glow).(color.NRGBA)
+	}
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			dx := (float64(x) + 0.5 - centre) / rx
+			dy := (float64(y) + 0.5 - centre) / ry
+
+			// Approximate distance inside the limb in pixels, negative outside
+			inside := (1 - math.Hypot(dx, dy)) * (rx + ry) / 2
+
+			if alpha := (inside + 0.5) / feather; alpha > 0 {
+				c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+				c.A = uint8(float64(c.A) * math.Min(1, alpha))
+				out.SetNRGBA(x, y, c)
+				continue
+			}
+
+			if opts.Glow != nil && opts.GlowWidth > 0 && -inside < opts.GlowWidth {
+				fade := 1 + inside/opts.GlowWidth
+				c := glow
+				c.A = uint8(float64(glow.A) * fade * fade)
+				out.SetNRGBA(x, y, c)
+			}
+		}
+	}
+
+	return out
+}
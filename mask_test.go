@@ -0,0 +1,48 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// TestMaskDisk tests that everything outside the Earth's limb is made
+// transparent, and that the feathered edge and glow are drawn around it.
+func TestMaskDisk(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 550, 550))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+
+	glow := color.NRGBA{100, 150, 255, 255}
+	radius := NewProjection(550).DiskRadius()
+
+	maskTests := []struct {
+		name   string
+		opts   DiskMaskOptions
+		x, y   int
+		alpha  uint8
+		isGlow bool
+	}{
+		{"Centre is opaque", DiskMaskOptions{}, 275, 275, 255, false},
+		{"Corner is transparent", DiskMaskOptions{}, 0, 0, 0, false},
+		{"Just outside the limb is transparent", DiskMaskOptions{}, int(275 + radius + 2), 275, 0, false},
+		{"Feathered edge is translucent", DiskMaskOptions{Feather: 20}, int(275 + radius - 10), 275, 127, false},
+		{"Glow outside the limb", DiskMaskOptions{Glow: glow, GlowWidth: 20}, int(275 + radius + 2), 275, 200, true},
+		{"Glow fades out", DiskMaskOptions{Glow: glow, GlowWidth: 20}, int(275 + radius + 25), 275, 0, false},
+	}
+
+	for _, mt := range maskTests {
+		t.Run(mt.name, func(t *testing.T) {
+			c := MaskDisk(src, mt.opts).NRGBAAt(mt.x, mt.y)
+
+			// Alpha is anti-aliased so only needs to be close
+			if diff := int(c.A) - int(mt.alpha); diff < -30 || diff > 30 {
+				t.Errorf("Expected alpha of about %v at %v,%v, received %v", mt.alpha, mt.x, mt.y, c.A)
+			}
+
+			if mt.isGlow && (c.R != glow.R || c.G != glow.G) {
+				t.Errorf("Expected the glow colour %v at %v,%v, received %v", glow, mt.x, mt.y, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package himago
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version of himago recorded in the Metadata of every image written.
+// Releases may override it with
+//
+//	-ldflags "-X github.com/tscott0/himago.Version=x.y.z"
+var Version = "0.2.0"
+
+// Metadata describes how an image was made. It is embedded in PNG text
+// chunks and can be written to a JSON sidecar file, so that archived images
+// are self-describing and can be reproduced.
+type Metadata struct {
+	// Time is the resolved time of the image, after rounding and rollback.
+	// It is the zero time when unknown.
+	Time time.Time `json:"time"`
+
+	Band Band `json:"band"`
+	Zoom Zoom `json:"zoom"`
+
+	// Satellite is the name of the Provider the Tiles were downloaded
+	// from, such as "Himawari-8".
+	Satellite string `json:"satellite,omitempty"`
+
+	// URL is the address of the top left Tile. It is empty when the time
+	// of the image is unknown.
+	URL string `json:"url"`
+
+	// FG is the foreground Color. BG is the background Color, or the kind
+	// of Background such as "linear-gradient" when it is not a Color.
+	FG string `json:"fg,omitempty"`
+	BG string `json:"bg,omitempty"`
+
+	Version string `json:"version"`
+}
+
+// NewMetadata describes a Capture drawn with the given colours.
+func NewMetadata(c *Capture, bg Background, fg Color) *Metadata {
+	provider := c.provider()
+
+	meta := &Metadata{
+		Time:      c.Time.Time,
+		Band:      c.Band,
+		Zoom:      c.Zoom,
+		Satellite: provider.Name(),
+		BG:        backgroundName(bg),
+		Version:   Version,
+	}
+
+	if !c.Time.IsZero() {
+		meta.URL = provider.TileURL(c.Band, c.Time, c.Zoom.GridWidth(), 0, 0)
+	}
+
+	// The foreground colour is only used when drawing a band
+	if c.Band != Band(0) {
+		meta.FG = fg.String()
+	}
+
+	return meta
+}
+
+// textChunks returns the keyword/value pairs written as PNG text chunks.
+// "Software" and "Creation Time" are keywords predefined by the PNG
+// specification, the rest are prefixed with "himago:".
+func (m *Metadata) textChunks() ([][2]string, error) {
+	js, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := [][2]string{
+		{"Software", "himago " + m.Version},
+	}
+
+	if !m.Time.IsZero() {
+		chunks = append(chunks, [2]string{"Creation Time", m.Time.UTC().Format(time.RFC1123)})
+	}
+
+	chunks = append(chunks,
+		[2]string{"himago:band", strconv.Itoa(int(m.Band))},
+		[2]string{"himago:zoom", strconv.Itoa(int(m.Zoom))},
+	)
+
+	if m.URL != "" {
+		chunks = append(chunks, [2]string{"himago:url", m.URL})
+	}
+
+	chunks = append(chunks, [2]string{"himago:metadata", string(js)})
+
+	return chunks, nil
+}
+
+// SidecarName returns the name of the JSON sidecar for an image file,
+// e.g. "output.png" becomes "output.json".
+func SidecarName(fileName string) string {
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".json"
+}
+
+// WriteSidecar writes the Metadata as indented JSON next to the image
+// fileName, see SidecarName.
+func (m *Metadata) WriteSidecar(fileName string) error {
+	js, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(SidecarName(fileName), append(js, '\n'), 0644)
+}
+
+// pngSignature is the 8 byte header of every PNG file.
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// encodePNG encodes img as a PNG and inserts meta as iTXt chunks directly
+// after the IHDR chunk. The standard library encoder cannot write text
+// chunks itself. iTXt is used rather than tEXt so values are UTF-8.
+func encodePNG(w io.Writer, img image.Image, meta *Metadata) error {
+	if meta == nil {
+		return png.Encode(w, img)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	encoded := buf.Bytes()
+
+	// The signature is followed by IHDR which always has 13 bytes of data:
+	// length (4) + type (4) + data (13) + CRC (4)
+	ihdrEnd := len(pngSignature) + 4 + 4 + 13 + 4
+	if len(encoded) < ihdrEnd || string(encoded[12:16]) != "IHDR" {
+		return errors.New("unexpected PNG encoding")
+	}
+
+	chunks, err := meta.textChunks()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(encoded[:ihdrEnd]); err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		// keyword, null separator, compression flag and method,
+		// then empty language tag and translated keyword
+		var data bytes.Buffer
+		data.WriteString(chunk[0])
+		data.Write([]byte{0, 0, 0, 0, 0})
+		data.WriteString(chunk[1])
+
+		if err := writeChunk(w, "iTXt", data.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(encoded[ihdrEnd:])
+	return err
+}
+
+// writeChunk writes a single PNG chunk with its length and CRC.
+func writeChunk(w io.Writer, chunkType string, data []byte) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(len(data)))
+	copy(header[4:], chunkType)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+
+	for _, b := range [][]byte{header, data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadMetadata reads the Metadata embedded in a PNG written by himago.
+// It returns nil if the PNG has no himago metadata.
+func ReadMetadata(r io.Reader) (*Metadata, error) {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return nil, err
+	}
+	if string(signature) != pngSignature {
+		return nil, errors.New("not a PNG file")
+	}
+
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+
+		length := binary.BigEndian.Uint32(header)
+		chunkType := string(header[4:])
+
+		// Metadata is always written before the image data
+		if chunkType == "IDAT" || chunkType == "IEND" {
+			return nil, nil
+		}
+
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		if chunkType != "iTXt" {
+			continue
+		}
+
+		// keyword, compression flag, compression method, language tag,
+		// translated keyword, text
+		fields := bytes.SplitN(data[:length], []byte{0}, 6)
+		if len(fields) != 6 || string(fields[0]) != "himago:metadata" {
+			continue
+		}
+
+		var meta Metadata
+		if err := json.Unmarshal(fields[5], &meta); err != nil {
+			return nil, err
+		}
+
+		return &meta, nil
+	}
+}
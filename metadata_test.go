@@ -0,0 +1,54 @@
+package himago
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// TestEncodePNGMetadata tests that Metadata embedded by encodePNG can be
+// read back and that the image still decodes.
+func TestEncodePNGMetadata(t *testing.T) {
+	capture := &Capture{
+		Band: Band(13),
+		Zoom: Zoom(2),
+		Time: SatTime{time.Date(2017, 4, 29, 15, 40, 0, 0, time.UTC)},
+	}
+
+	var fg, bg Color
+	fg.Set("#1793d1")
+	bg.Set("#333333")
+
+	meta := NewMetadata(capture, bg, fg)
+
+	var buf bytes.Buffer
+	if err := encodePNG(&buf, image.NewGray(image.Rect(0, 0, 10, 10)), meta); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("Failed to decode PNG with metadata: %v", err)
+	}
+
+	read, err := ReadMetadata(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read == nil {
+		t.Fatal("No metadata found")
+	}
+
+	if !read.Time.Equal(meta.Time) || read.Band != meta.Band || read.Zoom != meta.Zoom ||
+		read.FG != "#1793d1" || read.BG != "#333333" || read.URL != meta.URL || read.Version != Version {
+		t.Errorf("Expected %+v, received %+v", *meta, *read)
+	}
+}
+
+// TestSidecarName tests the naming of JSON sidecar files.
+func TestSidecarName(t *testing.T) {
+	if name := SidecarName("dir/output.png"); name != "dir/output.json" {
+		t.Errorf("Expected \"dir/output.json\", received %q", name)
+	}
+}
@@ -0,0 +1,38 @@
+package himago
+
+import "time"
+
+// Metrics receives measurements from a Fetcher. It is deliberately small so
+// that it can be backed by any metrics library without himago depending on
+// one. The metrics package provides an implementation that serves the
+// Prometheus text format.
+type Metrics interface {
+	// ObserveRequest is called for every HTTP request. A status of 0
+	// means no response was received.
+	ObserveRequest(status int, duration time.Duration, bytes int)
+
+	// CacheHit is called when a response is served from the Cache,
+	// either because it was fresh or because the server returned 304.
+	CacheHit()
+
+	// CacheMiss is called when a full response had to be stored in the
+	// Cache.
+	CacheMiss()
+
+	// Rollback is called each time GetTiles rolls back the image time
+	// after receiving a "No Image" tile.
+	Rollback()
+
+	// ObserveStitch is called with the time taken to stitch Tiles into
+	// an image.
+	ObserveStitch(duration time.Duration)
+}
+
+// nopMetrics is used when a Fetcher has no Metrics.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(int, time.Duration, int) {}
+func (nopMetrics) CacheHit()                              {}
+func (nopMetrics) CacheMiss()                             {}
+func (nopMetrics) Rollback()                              {}
+func (nopMetrics) ObserveStitch(time.Duration)            {}
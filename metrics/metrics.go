@@ -0,0 +1,156 @@
+// Package metrics collects measurements from a himago.Fetcher and serves
+// them in the Prometheus text exposition format, without depending on the
+// Prometheus client library.
+//
+//	collector := metrics.NewCollector()
+//	fetcher := &himago.Fetcher{Metrics: collector}
+//	http.Handle("/metrics", collector)
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histograms.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// histogram is a cumulative Prometheus-style histogram.
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, le := range h.buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, le := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", name, le, h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %g\n%s_count %d\n", name, h.sum, name, h.count)
+}
+
+// Collector implements himago.Metrics and http.Handler.
+// It is safe for concurrent use.
+type Collector struct {
+	mu sync.Mutex
+
+	requests    map[int]uint64
+	latency     *histogram
+	bytes       uint64
+	cacheHits   uint64
+	cacheMisses uint64
+	rollbacks   uint64
+	stitch      *histogram
+}
+
+// NewCollector returns an empty Collector using DefaultBuckets.
+func NewCollector() *Collector {
+	return &Collector{
+		requests: make(map[int]uint64),
+		latency:  newHistogram(DefaultBuckets),
+		stitch:   newHistogram(DefaultBuckets),
+	}
+}
+
+// ObserveRequest records a tile request. A status of 0 is a failed request.
+func (c *Collector) ObserveRequest(status int, duration time.Duration, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests[status]++
+	c.latency.observe(duration.Seconds())
+	c.bytes += uint64(bytes)
+}
+
+// CacheHit records a response served from the cache.
+func (c *Collector) CacheHit() {
+	c.mu.Lock()
+	c.cacheHits++
+	c.mu.Unlock()
+}
+
+// CacheMiss records a response that had to be downloaded in full.
+func (c *Collector) CacheMiss() {
+	c.mu.Lock()
+	c.cacheMisses++
+	c.mu.Unlock()
+}
+
+// Rollback records a "No Image" rollback.
+func (c *Collector) Rollback() {
+	c.mu.Lock()
+	c.rollbacks++
+	c.mu.Unlock()
+}
+
+// ObserveStitch records how long it took to stitch Tiles into an image.
+func (c *Collector) ObserveStitch(duration time.Duration) {
+	c.mu.Lock()
+	c.stitch.observe(duration.Seconds())
+	c.mu.Unlock()
+}
+
+// Expose writes every metric in the Prometheus text format.
+func (c *Collector) Expose(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP himago_tile_requests_total Tile requests by HTTP status, 0 for failed requests.\n")
+	fmt.Fprintf(w, "# TYPE himago_tile_requests_total counter\n")
+
+	statuses := make([]int, 0, len(c.requests))
+	for status := range c.requests {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	for _, status := range statuses {
+		fmt.Fprintf(w, "himago_tile_requests_total{status=\"%d\"} %d\n", status, c.requests[status])
+	}
+
+	c.latency.write(w, "himago_tile_request_duration_seconds", "Latency of tile requests.")
+
+	counters := []struct {
+		name, help string
+		value      uint64
+	}{
+		{"himago_tile_bytes_total", "Bytes received in tile responses.", c.bytes},
+		{"himago_cache_hits_total", "Responses served from the cache.", c.cacheHits},
+		{"himago_cache_misses_total", "Responses downloaded in full.", c.cacheMisses},
+		{"himago_rollbacks_total", "Image times rolled back after a \"No Image\" tile.", c.rollbacks},
+	}
+
+	for _, counter := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n",
+			counter.name, counter.help, counter.name, counter.name, counter.value)
+	}
+
+	c.stitch.write(w, "himago_stitch_duration_seconds", "Time taken to stitch tiles into an image.")
+}
+
+// ServeHTTP serves the metrics, typically on /metrics.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	c.Expose(w)
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tscott0/himago"
+)
+
+// Collector must satisfy the interface used by himago.Fetcher.
+var _ himago.Metrics = (*Collector)(nil)
+
+// TestCollectorExpose tests that observations appear in the exposition.
+func TestCollectorExpose(t *testing.T) {
+	c := NewCollector()
+	c.ObserveRequest(200, 200*time.Millisecond, 1024)
+	c.ObserveRequest(200, 2*time.Second, 1024)
+	c.ObserveRequest(404, time.Millisecond, 0)
+	c.CacheHit()
+	c.Rollback()
+
+	var buf bytes.Buffer
+	c.Expose(&buf)
+	out := buf.String()
+
+	expected := []string{
+		`himago_tile_requests_total{status="200"} 2`,
+		`himago_tile_requests_total{status="404"} 1`,
+		`himago_tile_request_duration_seconds_bucket{le="0.25"} 2`,
+		`himago_tile_request_duration_seconds_bucket{le="+Inf"} 3`,
+		`himago_tile_bytes_total 2048`,
+		`himago_cache_hits_total 1`,
+		`himago_cache_misses_total 0`,
+		`himago_rollbacks_total 1`,
+		`himago_stitch_duration_seconds_count 0`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Expected %q in:\n%v", line, out)
+		}
+	}
+}
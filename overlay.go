@@ -0,0 +1,166 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// LatLon is a point on the Earth in degrees.
+type LatLon struct {
+	Lat float64
+	Lon float64
+}
+
+// Path is a line joining a sequence of points, such as a coastline.
+type Path []LatLon
+
+// Stroke describes how lines are drawn.
+type Stroke struct {
+	// Color defaults to white.
+	Color color.Color
+
+	// Width is the line width in pixels.
+	Width float64
+
+	// Opacity scales the alpha of Color, between 0 and 1.
+	// 0 is treated as 1 so that the zero value is opaque.
+	Opacity float64
+
+	// Provider is the Provider the image came from, which sets its
+	// Projection. If nil, the DefaultFetcher's Provider is assumed.
+	Provider Provider
+}
+
+// maxSegment is the longest segment, in degrees, drawn as a straight line.
+// Longer segments are split so that they follow the curve of the Earth.
+const maxSegment = 1.0
+
+// Graticule returns lines of latitude and longitude every step degrees.
+// It returns nil if step is not positive.
+func Graticule(step float64) []Path {
+	if !(step > 0) {
+		return nil
+	}
+
+	var paths []Path
+
+	for lat := -90 + step; lat < 90; lat += step {
+		var path Path
+		for lon := -180.0; lon <= 180; lon += maxSegment {
+			path = append(path, LatLon{lat, lon})
+		}
+		paths = append(paths, path)
+	}
+
+	for lon := -180.0; lon < 180; lon += step {
+		var path Path
+		for lat := -90.0; lat <= 90; lat += maxSegment {
+			path = append(path, LatLon{lat, lon})
+		}
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+// DrawPaths draws paths onto a full-disk image with the given Stroke.
+// Parts of a path on the far side of the Earth are not drawn.
+func DrawPaths(dst draw.Image, paths []Path, stroke Stroke) {
+	bounds := dst.Bounds()
+	projection := ProviderProjection(stroke.Provider, bounds.Dx())
+
+	c := stroke.Color
+	if c == nil {
+		c = color.White
+	}
+
+	// Lines are rasterised into a coverage mask first so that overlapping
+	// segments do not build up opacity.
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for _, path := range paths {
+		for i := 1; i < len(path); i++ {
+			a, b := path[i-1], path[i]
+
+			// Paths crossing the antimeridian jump from 180 to -180
+			if math.Abs(a.Lon-b.Lon) > 180 {
+				continue
+			}
+
+			steps := int(math.Ceil(math.Max(math.Abs(b.Lat-a.Lat), math.Abs(b.Lon-a.Lon)) / maxSegment))
+			if steps < 1 {
+				steps = 1
+			}
+
+			x0, y0, visible0 := projection.ToPixel(a.Lat, a.Lon)
+			for s := 1; s <= steps; s++ {
+				t := float64(s) / float64(steps)
+				x1, y1, visible1 := projection.ToPixel(a.Lat+(b.Lat-a.Lat)*t, a.Lon+(b.Lon-a.Lon)*t)
+
+				if visible0 && visible1 {
+					strokeSegment(mask, x0, y0, x1, y1, stroke.Width)
+				}
+
+				x0, y0, visible0 = x1, y1, visible1
+			}
+		}
+	}
+
+	drawMask(dst, mask, c, stroke.Opacity)
+}
+
+// strokeSegment sets the coverage of every pixel within width/2 of the
+// segment from x0, y0 to x1, y1. Edges are anti-aliased over one pixel.
+func strokeSegment(mask *image.Alpha, x0, y0, x1, y1, width float64) {
+	radius := math.Max(width, 1) / 2
+
+	minX := int(math.Floor(math.Min(x0, x1) - radius - 1))
+	maxX := int(math.Ceil(math.Max(x0, x1) + radius + 1))
+	minY := int(math.Floor(math.Min(y0, y1) - radius - 1))
+	maxY := int(math.Ceil(math.Max(y0, y1) + radius + 1))
+
+	area := image.Rect(minX, minY, maxX, maxY).Intersect(mask.Bounds())
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			d := distanceToSegment(float64(x)+0.5, float64(y)+0.5, x0, y0, x1, y1)
+			coverage := math.Max(0, math.Min(1, radius+0.5-d))
+			if coverage == 0 {
+				continue
+			}
+
+			a := uint8(coverage * 255)
+			if a > mask.AlphaAt(x, y).A {
+				mask.SetAlpha(x, y, color.Alpha{a})
+			}
+		}
+	}
+}
+
+// distanceToSegment returns the distance from px, py to the nearest point
+// on the segment from x0, y0 to x1, y1.
+func distanceToSegment(px, py, x0, y0, x1, y1 float64) float64 {
+	dx, dy := x1-x0, y1-y0
+
+	t := 0.0
+	if length := dx*dx + dy*dy; length > 0 {
+		t = math.Max(0, math.Min(1, ((px-x0)*dx+(py-y0)*dy)/length))
+	}
+
+	return math.Hypot(px-(x0+t*dx), py-(y0+t*dy))
+}
+
+// drawMask draws c through the coverage mask onto dst, scaling the
+// coverage by opacity. An opacity of 0 is treated as 1.
+func drawMask(dst draw.Image, mask *image.Alpha, c color.Color, opacity float64) {
+	if opacity > 0 && opacity < 1 {
+		for i, a := range mask.Pix {
+			mask.Pix[i] = uint8(float64(a) * opacity)
+		}
+	}
+
+	bounds := dst.Bounds()
+	draw.DrawMask(dst, bounds, image.NewUniform(c), image.ZP, mask, image.ZP, draw.Over)
+}
@@ -0,0 +1,77 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// TestDrawPathsGraticule tests that a graticule is drawn on the disk only.
+func TestDrawPathsGraticule(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	red := color.RGBA{255, 0, 0, 255}
+
+	DrawPaths(img, Graticule(10), Stroke{Color: red, Width: 1})
+
+	// The equator passes through the centre of the image
+	if c := img.RGBAAt(100, 100); c.R == 0 {
+		t.Errorf("Expected the equator to be drawn at the centre, received %v", c)
+	}
+
+	// Nothing is drawn in the corners, off the disk
+	if c := img.RGBAAt(2, 2); c.A != 0 {
+		t.Errorf("Expected nothing to be drawn off the disk, received %v", c)
+	}
+}
+
+// TestDrawPathsZeroStroke tests that the zero Stroke draws white lines.
+func TestDrawPathsZeroStroke(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+
+	DrawPaths(img, Graticule(10), Stroke{})
+
+	// The line is anti-aliased, so the equator may only partly cover the
+	// centre pixel
+	if c := img.RGBAAt(100, 100); c.A == 0 || c.R != c.A || c.G != c.A || c.B != c.A {
+		t.Errorf("Expected white at the centre, received %v", c)
+	}
+}
+
+// TestDrawPathsProvider tests that paths are projected for the Stroke's
+// Provider.
+func TestDrawPathsProvider(t *testing.T) {
+	meridian := Path{{-10, -75.2}, {10, -75.2}}
+
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	DrawPaths(img, []Path{meridian}, Stroke{Provider: fakeProvider{}})
+	if c := img.RGBAAt(100, 100); c.A == 0 {
+		t.Errorf("Expected the meridian below the satellite to be drawn, received %v", c)
+	}
+
+	img = image.NewRGBA(image.Rect(0, 0, 200, 200))
+	DrawPaths(img, []Path{meridian}, Stroke{})
+	if c := img.RGBAAt(100, 100); c.A != 0 {
+		t.Errorf("Expected nothing drawn at the centre for Himawari 8, received %v", c)
+	}
+}
+
+// TestGraticuleStep tests that no lines are returned for a step that is
+// not positive, which would otherwise never finish.
+func TestGraticuleStep(t *testing.T) {
+	for _, step := range []float64{0, -10, math.NaN()} {
+		if paths := Graticule(step); paths != nil {
+			t.Errorf("Expected no paths for step %v, received %v", step, len(paths))
+		}
+	}
+}
+
+// TestDistanceToSegment tests distances to the middle and ends of a segment.
+func TestDistanceToSegment(t *testing.T) {
+	if d := distanceToSegment(5, 3, 0, 0, 10, 0); d != 3 {
+		t.Errorf("Expected 3, received %v", d)
+	}
+	if d := distanceToSegment(13, 4, 0, 0, 10, 0); math.Abs(d-5) > 1e-9 {
+		t.Errorf("Expected 5, received %v", d)
+	}
+}
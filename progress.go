@@ -0,0 +1,73 @@
+package himago
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Progress describes how far through an image download GetTiles is.
+type Progress struct {
+	TilesDone  int
+	TilesTotal int
+
+	// Bytes is the total size of all tile responses so far, including
+	// any that were discarded when rolling back.
+	Bytes int64
+
+	// Retries counts the number of times the image time was rolled back
+	// because a "No Image" tile was returned.
+	Retries int
+
+	// Time is the image time currently being downloaded.
+	Time SatTime
+}
+
+// Fraction returns the proportion of Tiles downloaded, between 0 and 1.
+func (p Progress) Fraction() float64 {
+	if p.TilesTotal == 0 {
+		return 0
+	}
+
+	return float64(p.TilesDone) / float64(p.TilesTotal)
+}
+
+// progressBarWidth is the number of characters between the brackets of
+// the progress bar.
+const progressBarWidth = 40
+
+// TextProgress returns a function suitable for Fetcher.Progress that writes
+// human-readable progress to w.
+// On a terminal (tty is true) a single progress bar is redrawn in place.
+// Otherwise a line is written every time another 10% of Tiles complete,
+// which keeps log files readable.
+func TextProgress(w io.Writer, tty bool) func(Progress) {
+	lastDecile := -1
+
+	return func(p Progress) {
+		if tty {
+			filled := int(p.Fraction() * progressBarWidth)
+			fmt.Fprintf(w, "\r[%s%s] %d/%d tiles %.1f MB %s",
+				strings.Repeat("=", filled),
+				strings.Repeat(" ", progressBarWidth-filled),
+				p.TilesDone, p.TilesTotal,
+				float64(p.Bytes)/1e6,
+				p.Time.Format("2006-01-02 15:04"))
+
+			if p.TilesDone == p.TilesTotal {
+				fmt.Fprintln(w)
+			}
+			return
+		}
+
+		decile := int(p.Fraction() * 10)
+		if decile == lastDecile {
+			return
+		}
+		lastDecile = decile
+
+		fmt.Fprintf(w, "%d/%d tiles (%d%%) %d bytes %d rollbacks %s\n",
+			p.TilesDone, p.TilesTotal, decile*10, p.Bytes, p.Retries,
+			p.Time.Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,54 @@
+package himago
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGetTilesProgress tests that Progress is reported once per Tile.
+func TestGetTilesProgress(t *testing.T) {
+	f := newTileServer(t)
+
+	var reports []Progress
+	f.Progress = func(p Progress) {
+		reports = append(reports, p)
+	}
+
+	_, err := f.GetTiles(Band(0), Zoom(2), SatTime{time.Date(2017, 4, 29, 15, 45, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) != 4 {
+		t.Fatalf("Expected 4 progress reports, received %v", len(reports))
+	}
+
+	last := reports[len(reports)-1]
+	if last.TilesDone != 4 || last.TilesTotal != 4 {
+		t.Errorf("Expected 4/4 tiles, received %v/%v", last.TilesDone, last.TilesTotal)
+	}
+	if last.Bytes == 0 {
+		t.Errorf("Expected bytes to be counted")
+	}
+	if last.Time.Minute() != 40 {
+		t.Errorf("Expected the rounded time, received %v", last.Time)
+	}
+}
+
+// TestTextProgress tests that periodic lines are only written when
+// another 10% of Tiles has completed.
+func TestTextProgress(t *testing.T) {
+	var buf bytes.Buffer
+	report := TextProgress(&buf, false)
+
+	for done := 1; done <= 64; done++ {
+		report(Progress{TilesDone: done, TilesTotal: 64})
+	}
+
+	lines := strings.Count(buf.String(), "\n")
+	if lines != 11 {
+		t.Errorf("Expected 11 lines, received %v:\n%v", lines, buf.String())
+	}
+}
@@ -0,0 +1,132 @@
+package himago
+
+import (
+	"fmt"
+	"time"
+)
+
+// Provider describes where a geostationary satellite's full-disk images are
+// published and how they are divided into Tiles. A Fetcher uses a Provider
+// to build every URL it requests, so a different satellite, or a local test
+// server, can be used without changing anything else.
+type Provider interface {
+	// Name of the satellite, such as "Himawari-8".
+	Name() string
+
+	// TileURL returns the URL of the Tile in column x and row y of a
+	// gridWidth x gridWidth image of band taken at t.
+	TileURL(band Band, t SatTime, gridWidth, x, y int) string
+
+	// LatestURL returns the URL of a JSON document whose "date" field is
+	// the time of the most recent image, formatted "2006-01-02 15:04:05".
+	LatestURL() string
+
+	// TileSize is the width and height of every Tile in pixels.
+	TileSize() int
+
+	// GridWidths lists the supported number of Tiles across an image.
+	GridWidths() []int
+
+	// Bands lists the supported bands. Band 0 is the true-colour image.
+	Bands() []Band
+
+	// Cadence is the interval between full-disk images.
+	Cadence() time.Duration
+
+	// SubSatelliteLon is the longitude the satellite is stationed above.
+	SubSatelliteLon() float64
+}
+
+// Himawari is a Provider for the Himawari 8 images published by NICT and
+// for servers that mirror their layout.
+type Himawari struct {
+	// Satellite is the name returned by Name.
+	Satellite string
+
+	// BaseURL is the prefix of every URL, ending in a slash.
+	BaseURL string
+}
+
+// Himawari8 serves images from the NICT Himawari 8 archive. It is the
+// default Provider.
+var Himawari8 = &Himawari{Satellite: "Himawari-8", BaseURL: uRLPrefix}
+
+// Name returns the name of the satellite.
+func (h *Himawari) Name() string {
+	return h.Satellite
+}
+
+// bandURL returns a URL in Sprintf format for a band, see Band.URL.
+func (h *Himawari) bandURL(band Band) string {
+	if band == Band(0) {
+		return h.BaseURL + "D531106" + uRLSuffix
+	}
+
+	return fmt.Sprintf("%sFULL_24h/B%02d%s", h.BaseURL, band, uRLSuffix)
+}
+
+// TileURL returns the URL of a single Tile.
+func (h *Himawari) TileURL(band Band, t SatTime, gridWidth, x, y int) string {
+	return fmt.Sprintf(h.bandURL(band),
+		gridWidth,
+		t.Year(),
+		int(t.Month()),
+		t.Day(),
+		t.Hour(),
+		t.Minute(),
+		x,
+		y)
+}
+
+// LatestURL returns the URL of latest.json.
+func (h *Himawari) LatestURL() string {
+	return h.BaseURL + "D531106/latest.json"
+}
+
+// TileSize is always 550 pixels.
+func (h *Himawari) TileSize() int {
+	return defaultTileSize
+}
+
+// GridWidths are those of Zoom 1 to 5.
+func (h *Himawari) GridWidths() []int {
+	return []int{1, 2, 4, 8, 16}
+}
+
+// Bands returns the true-colour band followed by bands 1 to 16.
+func (h *Himawari) Bands() []Band {
+	bands := []Band{0}
+	for _, info := range Bands() {
+		bands = append(bands, info.Band)
+	}
+	return bands
+}
+
+// Cadence is every 10 minutes.
+func (h *Himawari) Cadence() time.Duration {
+	return 10 * time.Minute
+}
+
+// SubSatelliteLon returns SubSatelliteLon.
+func (h *Himawari) SubSatelliteLon() float64 {
+	return SubSatelliteLon
+}
+
+// supports reports whether p can serve band at gridWidth.
+func supports(p Provider, band Band, gridWidth int) error {
+	found := false
+	for _, b := range p.Bands() {
+		found = found || b == band
+	}
+	if !found {
+		return fmt.Errorf("%v does not provide band %v", p.Name(), int(band))
+	}
+
+	for _, w := range p.GridWidths() {
+		if w == gridWidth {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v does not provide a %vx%v grid", p.Name(), gridWidth, gridWidth)
+}
@@ -0,0 +1,126 @@
+package himago
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHimawariTileURL tests that the Himawari8 Provider builds the same
+// URLs as Band.URL.
+func TestHimawariTileURL(t *testing.T) {
+	tm := SatTime{time.Date(2017, 2, 3, 19, 10, 0, 0, time.UTC)}
+
+	urlTests := []struct {
+		name string
+		band Band
+		out  string
+	}{
+		{"True colour", Band(0), "http://himawari8-dl.nict.go.jp/himawari8/img/D531106/2d/550/2017/02/03/191000_1_0.png"},
+		{"Band 13", Band(13), "http://himawari8-dl.nict.go.jp/himawari8/img/FULL_24h/B13/2d/550/2017/02/03/191000_1_0.png"},
+	}
+
+	for _, ut := range urlTests {
+		t.Run(ut.name, func(t *testing.T) {
+			if url := Himawari8.TileURL(ut.band, tm, 2, 1, 0); url != ut.out {
+				t.Errorf("Expected %v, received %v", ut.out, url)
+			}
+		})
+	}
+}
+
+// fakeProvider is a satellite with 100 pixel tiles every 5 minutes.
+type fakeProvider struct {
+	baseURL string
+}
+
+func (p fakeProvider) Name() string { return "Fake" }
+func (p fakeProvider) TileURL(band Band, t SatTime, gridWidth, x, y int) string {
+	return fmt.Sprintf("%v/%v/%v/%v_%v_%v.png", p.baseURL, int(band), gridWidth, t.Format("150405"), x, y)
+}
+func (p fakeProvider) LatestURL() string        { return p.baseURL + "/latest.json" }
+func (p fakeProvider) TileSize() int            { return 100 }
+func (p fakeProvider) GridWidths() []int        { return []int{1, 2} }
+func (p fakeProvider) Bands() []Band            { return []Band{0, 13} }
+func (p fakeProvider) Cadence() time.Duration   { return 5 * time.Minute }
+func (p fakeProvider) SubSatelliteLon() float64 { return -75.2 }
+
+// TestFetcherProvider tests that a Fetcher requests Tiles from its Provider.
+func TestFetcherProvider(t *testing.T) {
+	tile := blankTile(t, 100)
+
+	var requested []string
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path == "/latest.json" {
+			w.Write([]byte(`{"date":"2019-09-01 12:35:00"}`))
+			return
+		}
+		w.Write(tile)
+	})
+
+	f := &Fetcher{Provider: fakeProvider{server.URL}}
+
+	latest, err := f.LatestTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2019, 9, 1, 12, 35, 0, 0, time.UTC); !latest.Equal(expected) {
+		t.Errorf("Expected %v, received %v", expected, latest)
+	}
+
+	capture, err := f.GetCapture(Band(13), Zoom(2), SatTime{time.Date(2019, 9, 1, 12, 38, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tm := capture.Time.Format("15:04"); tm != "12:35" {
+		t.Errorf("Expected 12:35, the 5 minute cadence, received %v", tm)
+	}
+	if !strings.HasPrefix(requested[1], "/13/2/123500_") {
+		t.Errorf("Expected a tile from the Provider's layout, received %v", requested[1])
+	}
+
+	img := Stitch(capture.Band, capture.Tiles, Color{}, Color{})
+	if width := img.Bounds().Dx(); width != 200 {
+		t.Errorf("Expected a width of 200, received %v", width)
+	}
+
+	if p := ProviderProjection(f.Provider, 200); p.SubLon != -75.2 {
+		t.Errorf("Expected a SubLon of -75.2, received %v", p.SubLon)
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		if _, err := f.GetCapture(Band(3), Zoom(2), latest); err == nil {
+			t.Error("Expected an error for an unsupported band")
+		}
+		if _, err := f.GetCapture(Band(13), Zoom(3), latest); err == nil {
+			t.Error("Expected an error for an unsupported grid width")
+		}
+	})
+}
+
+// TestCaptureProvider tests that a Capture's Projection and Metadata come
+// from the Provider it was downloaded from.
+func TestCaptureProvider(t *testing.T) {
+	capture := &Capture{
+		Band:     Band(13),
+		Zoom:     Zoom(2),
+		Time:     SatTime{time.Date(2017, 4, 29, 15, 40, 0, 0, time.UTC)},
+		Provider: fakeProvider{"http://example.com"},
+	}
+
+	if p := capture.Projection(); p != (Projection{Size: 200, SubLon: -75.2}) {
+		t.Errorf("Expected a 200 pixel Projection at -75.2, received %+v", p)
+	}
+
+	meta := NewMetadata(capture, Color{}, Color{})
+	if meta.Satellite != "Fake" {
+		t.Errorf("Expected \"Fake\", received %q", meta.Satellite)
+	}
+	if meta.URL != "http://example.com/13/2/154000_0_0.png" {
+		t.Errorf("Expected the URL of the top left Tile, received %q", meta.URL)
+	}
+}
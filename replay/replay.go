@@ -0,0 +1,323 @@
+// Package replay records the responses to a himago.Fetcher's requests and
+// plays them back later without touching the network, so that bug reports
+// can be reproduced exactly and tests can run on real imagery offline.
+//
+//	recorder := replay.Record("testdata/session", nil)
+//	fetcher := &himago.Fetcher{Client: &http.Client{Transport: recorder}}
+//
+// and later
+//
+//	replayer, err := replay.Load("testdata/session")
+//	fetcher := &himago.Fetcher{Client: &http.Client{Transport: replayer}}
+//
+// A recording is a directory, or a tarball of one, holding two files per
+// request named after the SHA-1 hash of its method and URL: the raw body
+// and a JSON file with the URL, status and headers.
+package replay
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entry is a single recorded response.
+type entry struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+
+	body []byte
+}
+
+// key names the files of the entry for a request.
+func key(method, url string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(method+" "+url)))
+}
+
+// Recorder is an http.RoundTripper that passes every request on to
+// Transport and saves the response. The response is returned unchanged.
+type Recorder struct {
+	// Transport sends the requests. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	mu    sync.Mutex
+	dir   string
+	tar   *tar.Writer
+	saved int
+
+	// succeeded holds the keys of requests with a 2xx response recorded.
+	succeeded map[string]bool
+}
+
+// Record returns a Recorder that saves responses into dir, creating it if
+// necessary. Later responses for the same request replace earlier ones,
+// except that once a 2xx response is recorded it is kept. A 304 Not
+// Modified from revalidating a cached response would otherwise leave
+// nothing to replay without the cache.
+func Record(dir string, transport http.RoundTripper) *Recorder {
+	return &Recorder{Transport: transport, dir: dir, succeeded: map[string]bool{}}
+}
+
+// RecordTar returns a Recorder that writes responses to w as a tar
+// stream, keeping responses as Record does. Close must be called to
+// finish the archive.
+func RecordTar(w io.Writer, transport http.RoundTripper) *Recorder {
+	return &Recorder{Transport: transport, tar: tar.NewWriter(w), succeeded: map[string]bool{}}
+}
+
+// Saved returns the number of responses recorded so far.
+func (r *Recorder) Saved() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saved
+}
+
+// Close finishes a tarball. It does nothing when recording to a directory.
+func (r *Recorder) Close() error {
+	if r.tar == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tar.Close()
+}
+
+// RoundTrip sends the request and records the response.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	e := &entry{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: response.StatusCode,
+		Header: response.Header,
+		body:   body,
+	}
+
+	if err := r.save(e); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// save writes the body and then the JSON description of an entry.
+func (r *Recorder) save(e *entry) error {
+	meta, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	name := key(e.Method, e.URL)
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{name + ".body", e.body},
+		{name + ".json", meta},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.succeeded[name] {
+		return nil
+	}
+
+	if r.tar == nil {
+		if err := os.MkdirAll(r.dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		if r.tar == nil {
+			err = os.WriteFile(filepath.Join(r.dir, f.name), f.data, 0644)
+		} else {
+			err = r.writeTarFile(f.name, f.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	r.saved++
+	if e.Status >= 200 && e.Status < 300 {
+		r.succeeded[name] = true
+	}
+	return nil
+}
+
+func (r *Recorder) writeTarFile(name string, data []byte) error {
+	err := r.tar.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.tar.Write(data)
+	return err
+}
+
+// Replayer is an http.RoundTripper that answers requests only from a
+// recording. Requests that were not recorded fail with an error.
+type Replayer struct {
+	entries map[string]*entry
+}
+
+// Load reads a recording from a directory, or from a tarball which may be
+// gzip compressed.
+func Load(path string) (*Replayer, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+
+	if info.IsDir() {
+		names, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			if n.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(path, n.Name()))
+			if err != nil {
+				return nil, err
+			}
+			files[n.Name()] = data
+		}
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if err := readTar(f, files); err != nil {
+			return nil, fmt.Errorf("reading %v: %v", path, err)
+		}
+	}
+
+	r := &Replayer{entries: map[string]*entry{}}
+
+	for name, meta := range files {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		var e entry
+		if err := json.Unmarshal(meta, &e); err != nil {
+			return nil, fmt.Errorf("reading %v: %v", name, err)
+		}
+
+		body, ok := files[strings.TrimSuffix(name, ".json")+".body"]
+		if !ok {
+			return nil, fmt.Errorf("recording %v has no body", name)
+		}
+		e.body = body
+
+		r.entries[key(e.Method, e.URL)] = &e
+	}
+
+	return r, nil
+}
+
+// readTar adds every file in a tarball to files. Later files replace
+// earlier ones with the same name.
+func readTar(r io.Reader, files map[string][]byte) error {
+	buffered := bufio.NewReader(r)
+
+	// gzip streams start with 0x1f 0x8b
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return err
+		}
+		files[filepath.Base(header.Name)] = data
+	}
+}
+
+// Len returns the number of recorded responses.
+func (r *Replayer) Len() int {
+	return len(r.entries)
+}
+
+// RoundTrip returns the recorded response to the request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	e, ok := r.entries[key(req.Method, req.URL.String())]
+	if !ok {
+		return nil, fmt.Errorf("replay: no recording of %v %v", req.Method, req.URL)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tscott0/himago"
+	"github.com/tscott0/himago/himagotest"
+)
+
+var imageTime = himago.SatTime{Time: time.Date(2017, 4, 29, 15, 40, 0, 0, time.UTC)}
+
+// record downloads a capture through a Recorder, rolling back once, then
+// shuts the server down so that only the recording is left. recorder may
+// return a Closer, such as the tarball's file, which is closed once the
+// Recorder has finished.
+func record(t *testing.T, recorder func(http.RoundTripper) (*Recorder, io.Closer)) *himago.Himawari {
+	server := himagotest.NewServer()
+	defer server.Close()
+
+	server.SetNoImage(imageTime)
+
+	r, closer := recorder(server.Client().Transport)
+	f := server.Fetcher()
+	f.Client = &http.Client{Transport: r}
+
+	if _, err := f.GetCapture(himago.Band(13), himago.Zoom(2), imageTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if closer != nil {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// One No Image and four tiles
+	if r.Saved() != 5 {
+		t.Errorf("Expected 5 saved responses, received %v", r.Saved())
+	}
+
+	return server.Provider()
+}
+
+// TestRecordReplay tests that a capture can be replayed from each kind of
+// recording once the server has gone.
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	recordTests := []struct {
+		name   string
+		path   string
+		record func(http.RoundTripper) (*Recorder, io.Closer)
+	}{
+		{"Directory", filepath.Join(dir, "session"), func(transport http.RoundTripper) (*Recorder, io.Closer) {
+			return Record(filepath.Join(dir, "session"), transport), nil
+		}},
+		{"Tarball", filepath.Join(dir, "session.tar"), func(transport http.RoundTripper) (*Recorder, io.Closer) {
+			f, err := os.Create(filepath.Join(dir, "session.tar"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return RecordTar(f, transport), f
+		}},
+	}
+
+	for _, rt := range recordTests {
+		t.Run(rt.name, func(t *testing.T) {
+			provider := record(t, rt.record)
+
+			replayer, err := Load(rt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f := &himago.Fetcher{Client: &http.Client{Transport: replayer}, Provider: provider}
+			capture, err := f.GetCapture(himago.Band(13), himago.Zoom(2), imageTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if captureTime := capture.Time.Format("15:04"); captureTime != "15:30" {
+				t.Errorf("Expected the rolled back 15:30, received %v", captureTime)
+			}
+
+			if _, err := f.GetCapture(himago.Band(13), himago.Zoom(1), imageTime); err == nil {
+				t.Error("Expected an error for a request that was not recorded")
+			}
+		})
+	}
+}
+
+// TestRecordCached tests that a recording made through a Fetcher with a
+// Cache replays without one, although the server answered the second
+// request with 304 Not Modified.
+func TestRecordCached(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"date":"2017-04-29 15:40:00"}`))
+	}))
+	defer server.Close()
+
+	cache, err := himago.NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "session")
+	r := Record(dir, server.Client().Transport)
+	provider := &himago.Himawari{BaseURL: server.URL + "/"}
+	f := &himago.Fetcher{Client: &http.Client{Transport: r}, Cache: cache, Provider: provider}
+
+	for i := 0; i < 2; i++ {
+		if _, err := f.LatestTime(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if r.Saved() != 1 {
+		t.Errorf("Expected 1 saved response, received %v", r.Saved())
+	}
+
+	replayer, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f = &himago.Fetcher{Client: &http.Client{Transport: replayer}, Provider: provider}
+	latest, err := f.LatestTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Format("15:04") != "15:40" {
+		t.Errorf("Expected 15:40, received %v", latest.Format("15:04"))
+	}
+}
+
+// TestLoadGzip tests that gzip compressed tarballs are read.
+func TestLoadGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+
+	server := himagotest.NewServer()
+	defer server.Close()
+
+	r := RecordTar(gz, server.Client().Transport)
+	f := server.Fetcher()
+	f.Client = &http.Client{Transport: r}
+	if _, err := f.LatestTime(); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	gz.Close()
+
+	fileName := filepath.Join(t.TempDir(), "session.tar.gz")
+	if err := os.WriteFile(fileName, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := Load(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayer.Len() != 1 {
+		t.Errorf("Expected 1 response, received %v", replayer.Len())
+	}
+}
@@ -1,7 +1,6 @@
 package himago
 
 import (
-	"math"
 	"time"
 )
 
@@ -15,18 +14,30 @@ type SatTime struct {
 // the Minute value.  e.g. at 13:34 would become 13:30
 // Existing multiples of 10 minutes will not be affected.
 func (t *SatTime) Round() {
-	newMins := int(10 * math.Floor(float64(t.Minute())/10))
+	t.RoundTo(10 * time.Minute)
+}
 
+// RoundTo rounds down to the nearest multiple of cadence, e.g. a
+// Provider's Cadence. Like Round, the date and clock fields are kept and
+// taken to be UTC.
+func (t *SatTime) RoundTo(cadence time.Duration) {
 	t.Time = time.Date(t.Year(),
 		t.Month(),
 		t.Day(),
 		t.Hour(),
-		newMins,
-		0, 0, time.UTC)
+		t.Minute(),
+		t.Second(),
+		t.Nanosecond(), time.UTC).Truncate(cadence)
 }
 
 // Rollback 10 minutes. Assumes that the time was previously a
 // multiple of 10 minutes, which calling Round() guarantees.
 func (t *SatTime) Rollback() {
-	t.Time = t.Add(-10 * time.Minute)
+	t.RollbackBy(10 * time.Minute)
+}
+
+// RollbackBy rolls back one cadence. Assumes that the time was previously
+// a multiple of cadence, which calling RoundTo(cadence) guarantees.
+func (t *SatTime) RollbackBy(cadence time.Duration) {
+	t.Time = t.Add(-cadence)
 }
@@ -86,3 +86,31 @@ func TestRollback(t *testing.T) {
 		})
 	}
 }
+
+// TestRoundTo tests rounding to other cadences. Times in other zones keep
+// their clock, as with Round.
+func TestRoundTo(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+
+	roundToTests := []struct {
+		name    string
+		cadence time.Duration
+		in      time.Time
+		out     time.Time
+	}{
+		{"5 minutes", 5 * time.Minute, time.Date(2019, 9, 1, 12, 38, 20, 0, time.UTC), time.Date(2019, 9, 1, 12, 35, 0, 0, time.UTC)},
+		{"Hourly", time.Hour, time.Date(2019, 9, 1, 12, 38, 0, 0, time.UTC), time.Date(2019, 9, 1, 12, 0, 0, 0, time.UTC)},
+		{"Other zone", 10 * time.Minute, time.Date(2019, 9, 1, 12, 38, 0, 0, jst), time.Date(2019, 9, 1, 12, 30, 0, 0, time.UTC)},
+	}
+
+	for _, rt := range roundToTests {
+		t.Run(rt.name, func(t *testing.T) {
+			in := SatTime{rt.in}
+			in.RoundTo(rt.cadence)
+
+			if !in.Equal(rt.out) {
+				t.Errorf("Expected %v, received %v", rt.out, in.Time)
+			}
+		})
+	}
+}
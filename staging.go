@@ -0,0 +1,105 @@
+package himago
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// TileError is returned when a single Tile of a capture cannot be
+// downloaded. With a Fetcher.Staging directory the Tiles before it are
+// kept, so trying the capture again resumes from this Tile.
+type TileError struct {
+	Band Band
+	Zoom Zoom
+	Time SatTime
+
+	// X and Y are the column and row of the Tile.
+	X, Y int
+
+	URL string
+	Err error
+}
+
+func (e *TileError) Error() string {
+	return fmt.Sprintf("tile %v,%v of band %v at %v: %v", e.X, e.Y, int(e.Band), e.Time.Format("2006-01-02 15:04"), e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TileError) Unwrap() error {
+	return e.Err
+}
+
+// staging is the directory holding the Tiles of one capture.
+type staging struct {
+	dir string
+}
+
+// staging returns where the Tiles of a capture are staged, or nil if the
+// Fetcher has no Staging directory. Captures are keyed by the URL of their
+// first Tile, which identifies the provider, band, grid width and time.
+func (f *Fetcher) staging(provider Provider, band Band, t SatTime, gridWidth int) *staging {
+	if f.Staging == "" {
+		return nil
+	}
+
+	key := fmt.Sprintf("%x", sha1.Sum([]byte(provider.TileURL(band, t, gridWidth, 0, 0))))
+	return &staging{filepath.Join(f.Staging, key)}
+}
+
+func (s *staging) path(x, y int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%d_%d.png", x, y))
+}
+
+// load returns a previously staged Tile. Unreadable files are ignored so
+// the Tile is downloaded again.
+func (s *staging) load(x, y int) (Tile, bool) {
+	f, err := os.Open(s.path(x, y))
+	if err != nil {
+		return Tile{}, false
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return Tile{}, false
+	}
+
+	return Tile{img}, true
+}
+
+// save stages the encoded body of a Tile. It is written to a temporary
+// file first so that an interrupted write is never loaded.
+func (s *staging) save(x, y int, body []byte) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	tmp := s.path(x, y) + ".tmp"
+	if err := os.WriteFile(tmp, body, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path(x, y))
+}
+
+// remove deletes the staged Tiles once a capture is complete.
+func (s *staging) remove() error {
+	return os.RemoveAll(s.dir)
+}
+
+// stagedTile returns a staged Tile if there is one, otherwise it is
+// downloaded. The body is nil when the Tile came from the staging area.
+func (f *Fetcher) stagedTile(log *slog.Logger, stage *staging, url string, x, y int) (Tile, []byte, error) {
+	if stage != nil {
+		if tile, ok := stage.load(x, y); ok {
+			log.Debug("staged tile", "dir", stage.dir)
+			return tile, nil, nil
+		}
+	}
+
+	return f.downloadTile(log, url)
+}
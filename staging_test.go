@@ -0,0 +1,70 @@
+package himago
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestStagedCapture tests that a failed GetCapture keeps the Tiles it
+// downloaded, so a rerun only requests the missing ones.
+func TestStagedCapture(t *testing.T) {
+	tile := blankTile(t, defaultTileSize)
+
+	var mu sync.Mutex
+	fail := true
+	var requests []string
+
+	_, f := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests = append(requests, r.URL.Path)
+		if fail && strings.HasSuffix(r.URL.Path, "_1_0.png") {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(tile)
+	})
+
+	dir := t.TempDir()
+	f.Staging = dir
+	tm := SatTime{time.Date(2017, 4, 29, 15, 40, 0, 0, time.UTC)}
+
+	_, err := f.GetCapture(Band(13), Zoom(2), tm)
+
+	var tileErr *TileError
+	if !errors.As(err, &tileErr) {
+		t.Fatalf("Expected a *TileError, received %v", err)
+	}
+	if tileErr.X != 1 || tileErr.Y != 0 {
+		t.Errorf("Expected an error for tile 1,0, received %v,%v", tileErr.X, tileErr.Y)
+	}
+
+	mu.Lock()
+	fail = false
+	requests = nil
+	mu.Unlock()
+
+	capture, err := f.GetCapture(Band(13), Zoom(2), tm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capture.Tiles) != 2 || len(capture.Tiles[0]) != 2 {
+		t.Errorf("Expected 2x2 tiles, received %v columns", len(capture.Tiles))
+	}
+
+	// The first column was staged, so the rerun starts from the tile
+	// that failed
+	if len(requests) != 2 || !strings.HasSuffix(requests[0], "_1_0.png") {
+		t.Errorf("Expected requests for the 2 missing tiles, received %v", requests)
+	}
+
+	if staged, _ := os.ReadDir(dir); len(staged) != 0 {
+		t.Errorf("Expected no staged captures after completing, received %v", len(staged))
+	}
+}
@@ -0,0 +1,173 @@
+package himago
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// Region is a box of latitude and longitude in degrees.
+// If MinLon is greater than MaxLon the region crosses the antimeridian.
+type Region struct {
+	MinLat float64 `json:"minLat"`
+	MinLon float64 `json:"minLon"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLon float64 `json:"maxLon"`
+}
+
+// Contains returns true if the point is inside the Region.
+func (r *Region) Contains(lat, lon float64) bool {
+	if lat < r.MinLat || lat > r.MaxLat {
+		return false
+	}
+
+	if r.MinLon <= r.MaxLon {
+		return lon >= r.MinLon && lon <= r.MaxLon
+	}
+
+	return lon >= r.MinLon || lon <= r.MaxLon
+}
+
+// String outputs the Region in the same format that Set accepts.
+func (r *Region) String() string {
+	return fmt.Sprintf("%g,%g,%g,%g", r.MinLat, r.MinLon, r.MaxLat, r.MaxLon)
+}
+
+// Set accepts four numbers separated by commas: the minimum latitude and
+// longitude followed by the maximum latitude and longitude,
+// e.g. "-45,110,-10,155" for Australia.
+// Implements the flag.Value interface.
+func (r *Region) Set(value string) error {
+	fields := strings.Split(value, ",")
+	if len(fields) != 4 {
+		return errors.New("Region must be minLat,minLon,maxLat,maxLon")
+	}
+
+	var values [4]float64
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return fmt.Errorf("Invalid number %q in region", field)
+		}
+		values[i] = v
+	}
+
+	if values[0] > values[2] || values[0] < -90 || values[2] > 90 {
+		return errors.New("Region latitudes must be between -90 and 90, minimum first")
+	}
+
+	r.MinLat, r.MinLon, r.MaxLat, r.MaxLon = values[0], values[1], values[2], values[3]
+	return nil
+}
+
+// Stats summarises a band image.
+type Stats struct {
+	Band Band `json:"band"`
+
+	// Region is the area analysed, nil for the whole disk.
+	Region *Region `json:"region,omitempty"`
+
+	// Histogram counts the pixel intensities of the analysed pixels.
+	Histogram [256]int `json:"histogram"`
+
+	// Pixels is the number of pixels analysed.
+	Pixels int `json:"pixels"`
+
+	// DiskFraction is the fraction of the whole image covered by the
+	// Earth's disk.
+	DiskFraction float64 `json:"diskFraction"`
+
+	// CloudFraction is the estimated fraction of the analysed pixels that
+	// are cloud, see CloudThreshold.
+	CloudFraction float64 `json:"cloudFraction"`
+
+	// MeanBrightness is the mean intensity of the analysed pixels between
+	// 0 and 1.
+	MeanBrightness float64 `json:"meanBrightness"`
+}
+
+// visibleCloudThreshold is the intensity above which a pixel of a visible
+// or near-IR band is considered to be cloud.
+const visibleCloudThreshold = 160
+
+// infraredCloudTemperature is the brightness temperature in Kelvin below
+// which a pixel of an infrared band is considered to be cloud.
+const infraredCloudTemperature = 265
+
+// CloudThreshold returns the pixel intensity above which a pixel of the
+// Band is counted as cloud. Clouds are bright in the visible bands and
+// cold, which is also bright, in the infrared bands.
+func (b Band) CloudThreshold() uint8 {
+	if c, ok := b.Calibration(); ok {
+		return c.Value(infraredCloudTemperature)
+	}
+
+	return visibleCloudThreshold
+}
+
+// Analyse returns Stats for a full-disk image of band from provider, or
+// the DefaultFetcher's Provider if nil. Only pixels on the Earth's disk,
+// and inside region if it is not nil, are analysed. img should be drawn
+// with a white foreground on a black background, see Stitch.
+func Analyse(provider Provider, band Band, img image.Image, region *Region) Stats {
+	stats := Stats{Band: band, Region: region}
+
+	bounds := img.Bounds()
+	projection := ProviderProjection(provider, bounds.Dx())
+	threshold := band.CloudThreshold()
+
+	onDisk, cloudy, total := 0, 0, 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Sample the centre of the pixel
+			lat, lon, ok := projection.ToLatLon(float64(x-bounds.Min.X)+0.5, float64(y-bounds.Min.Y)+0.5)
+			if !ok {
+				continue
+			}
+			onDisk++
+
+			if region != nil && !region.Contains(lat, lon) {
+				continue
+			}
+
+			v := pixelValue(img, x, y)
+			stats.Histogram[v]++
+			stats.Pixels++
+			total += int(v)
+
+			if v > threshold {
+				cloudy++
+			}
+		}
+	}
+
+	if area := bounds.Dx() * bounds.Dy(); area > 0 {
+		stats.DiskFraction = float64(onDisk) / float64(area)
+	}
+
+	if stats.Pixels > 0 {
+		stats.CloudFraction = float64(cloudy) / float64(stats.Pixels)
+		stats.MeanBrightness = float64(total) / float64(stats.Pixels) / 255
+	}
+
+	return stats
+}
+
+// AnalyseTiles stitches the Tiles of band from provider and returns their
+// Stats.
+func AnalyseTiles(provider Provider, band Band, tiles [][]Tile, region *Region) Stats {
+	return Analyse(provider, band, stitchIntensity(band, tiles), region)
+}
+
+// stitchIntensity stitches Tiles white on black so that pixel intensity
+// is preserved for analysis.
+func stitchIntensity(band Band, tiles [][]Tile) *image.RGBA {
+	var black, white Color
+	black.A = 255
+	white.R, white.G, white.B, white.A = 255, 255, 255, 255
+
+	return Stitch(band, tiles, black, white)
+}
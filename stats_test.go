@@ -0,0 +1,84 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+// TestRegionSet tests parsing a Region from a command-line flag.
+func TestRegionSet(t *testing.T) {
+	var r Region
+	if err := r.Set("-45,110,-10,155"); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.String() != "-45,110,-10,155" {
+		t.Errorf("Expected \"-45,110,-10,155\", received %q", r.String())
+	}
+
+	for _, invalid := range []string{"", "1,2,3", "a,2,3,4", "10,0,-10,0", "-100,0,0,0"} {
+		if err := r.Set(invalid); err == nil {
+			t.Errorf("Calling region.Set(%q) should have thrown an error", invalid)
+		}
+	}
+}
+
+// TestRegionContains tests regions either side of the antimeridian.
+func TestRegionContains(t *testing.T) {
+	australia := Region{-45, 110, -10, 155}
+	if !australia.Contains(-33.87, 151.21) || australia.Contains(35.68, 139.69) {
+		t.Errorf("Unexpected result for Australia")
+	}
+
+	pacific := Region{-30, 170, 30, -170}
+	if !pacific.Contains(0, 180) || !pacific.Contains(0, -175) || pacific.Contains(0, 0) {
+		t.Errorf("Unexpected result for a region crossing the antimeridian")
+	}
+}
+
+// TestAnalyse tests the Stats of an image that is entirely bright.
+func TestAnalyse(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+
+	stats := Analyse(nil, Band(3), img, nil)
+
+	p := NewProjection(200)
+	expectedDisk := math.Pi * p.DiskRadius() * p.DiskRadius() / (200 * 200)
+	if math.Abs(stats.DiskFraction-expectedDisk) > 0.02 {
+		t.Errorf("Expected disk fraction near %v, received %v", expectedDisk, stats.DiskFraction)
+	}
+
+	if stats.CloudFraction != 1 || stats.MeanBrightness != 1 {
+		t.Errorf("Expected a completely cloudy image, received %+v", stats)
+	}
+
+	if stats.Histogram[255] != stats.Pixels {
+		t.Errorf("Expected every pixel in the top bucket")
+	}
+
+	region := Region{-10, 130, 10, 150}
+	regional := Analyse(nil, Band(3), img, &region)
+	if regional.Pixels == 0 || regional.Pixels >= stats.Pixels {
+		t.Errorf("Expected the region to contain fewer pixels, received %v", regional.Pixels)
+	}
+}
+
+// TestAnalyseProvider tests that regions are located using the Provider's
+// Projection.
+func TestAnalyseProvider(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 200, 200))
+
+	// Below the fake satellite, on the far side of the Earth for Himawari
+	region := Region{-10, -85, 10, -65}
+
+	if stats := Analyse(fakeProvider{}, Band(3), img, &region); stats.Pixels == 0 {
+		t.Errorf("Expected pixels in the region, received none")
+	}
+	if stats := Analyse(nil, Band(3), img, &region); stats.Pixels != 0 {
+		t.Errorf("Expected no pixels in the region, received %v", stats.Pixels)
+	}
+}
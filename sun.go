@@ -0,0 +1,195 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Solar elevations in degrees that bound day, twilight and night.
+const (
+	Sunset           = -0.833
+	CivilTwilight    = -6.0
+	NauticalTwilight = -12.0
+)
+
+const degreesPerRadian = 180 / math.Pi
+
+// SubsolarPoint returns the latitude and longitude in degrees where the sun
+// is directly overhead at the time t. It uses the NOAA solar position
+// approximation, which is accurate to a fraction of a degree.
+func SubsolarPoint(t SatTime) (lat, lon float64) {
+	utc := t.UTC()
+
+	// Fractional year in radians
+	dayOfYear := float64(utc.YearDay() - 1)
+	hours := float64(utc.Hour()) + float64(utc.Minute())/60 + float64(utc.Second())/3600
+	gamma := 2 * math.Pi / 365 * (dayOfYear + (hours-12)/24)
+
+	// Equation of time in minutes
+	eqTime := 229.18 * (0.000075 +
+		0.001868*math.Cos(gamma) -
+		0.032077*math.Sin(gamma) -
+		0.014615*math.Cos(2*gamma) -
+		0.040849*math.Sin(2*gamma))
+
+	// Solar declination in radians
+	decl := 0.006918 -
+		0.399912*math.Cos(gamma) +
+		0.070257*math.Sin(gamma) -
+		0.006758*math.Cos(2*gamma) +
+		0.000907*math.Sin(2*gamma) -
+		0.002697*math.Cos(3*gamma) +
+		0.00148*math.Sin(3*gamma)
+
+	lat = decl * degreesPerRadian
+
+	// The sun is overhead where solar time is noon
+	lon = -15 * (hours - 12 + eqTime/60)
+	lon = math.Mod(lon+540, 360) - 180
+
+	return lat, lon
+}
+
+// SolarElevation returns the elevation of the sun above the horizon in
+// degrees at a latitude and longitude at the time t.
+func SolarElevation(t SatTime, lat, lon float64) float64 {
+	sunLat, sunLon := SubsolarPoint(t)
+	return solarElevation(sunLat, sunLon, lat, lon)
+}
+
+func solarElevation(sunLat, sunLon, lat, lon float64) float64 {
+	sunLat, sunLon = sunLat/degreesPerRadian, sunLon/degreesPerRadian
+	lat, lon = lat/degreesPerRadian, lon/degreesPerRadian
+
+	// The zenith angle is the angular distance to the subsolar point
+	cosZenith := math.Sin(lat)*math.Sin(sunLat) + math.Cos(lat)*math.Cos(sunLat)*math.Cos(lon-sunLon)
+	return 90 - math.Acos(math.Max(-1, math.Min(1, cosZenith)))*degreesPerRadian
+}
+
+// TerminatorOptions configures DrawTerminator.
+type TerminatorOptions struct {
+	// Line is the colour of the terminator itself, where the sun sets.
+	Line color.Color
+
+	// Civil and Nautical are drawn over the twilight bands between the
+	// terminator and CivilTwilight, and between CivilTwilight and
+	// NauticalTwilight. Either may be nil.
+	Civil    color.Color
+	Nautical color.Color
+
+	// Night is drawn over everywhere darker than NauticalTwilight.
+	// It may be nil.
+	Night color.Color
+
+	// Provider is the Provider the image came from, which sets its
+	// Projection. If nil, the DefaultFetcher's Provider is assumed.
+	Provider Provider
+}
+
+// DrawTerminator draws the day/night terminator and twilight bands for
+// the time t onto a full-disk image. Colours with transparency can be used
+// to shade the bands without hiding the image.
+func DrawTerminator(dst draw.Image, t SatTime, opts TerminatorOptions) {
+	sunLat, sunLon := SubsolarPoint(t)
+
+	bounds := dst.Bounds()
+	projection := ProviderProjection(opts.Provider, bounds.Dx())
+
+	// The terminator is drawn where the elevation changes sign between
+	// neighbouring pixels, so it is about one pixel wide.
+	elevations := make([]float64, bounds.Dx()*bounds.Dy())
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			lat, lon, ok := projection.ToLatLon(float64(x)+0.5, float64(y)+0.5)
+			if !ok {
+				elevations[y*bounds.Dx()+x] = math.NaN()
+				continue
+			}
+			elevations[y*bounds.Dx()+x] = solarElevation(sunLat, sunLon, lat, lon)
+		}
+	}
+
+	// sunset reports whether the pixel at x, y is past sunset. Pixels
+	// off the image or off the disk are not.
+	sunset := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= bounds.Dx() || y >= bounds.Dy() {
+			return false
+		}
+		return elevations[y*bounds.Dx()+x] < Sunset
+	}
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			e := elevations[y*bounds.Dx()+x]
+			if math.IsNaN(e) {
+				continue
+			}
+
+			var c color.Color
+			switch {
+			case e >= Sunset:
+				// Checking all four neighbours draws the line whichever
+				// side of the day the night is on
+				if sunset(x-1, y) || sunset(x+1, y) || sunset(x, y-1) || sunset(x, y+1) {
+					c = opts.Line
+				}
+			case e >= CivilTwilight:
+				c = opts.Civil
+			case e >= NauticalTwilight:
+				c = opts.Nautical
+			default:
+				c = opts.Night
+			}
+
+			if c != nil {
+				blendPixel(dst, bounds.Min.X+x, bounds.Min.Y+y, c)
+			}
+		}
+	}
+}
+
+// DarkenNight scales the brightness of every pixel on the night side of a
+// full-disk image by factor, between 0 (black) and 1 (unchanged). The
+// darkening fades in through civil twilight so there is no hard edge.
+// This is intended for true-colour images, which show reflected sunlight
+// only and otherwise leave the night side a murky grey. dst is from
+// provider, or the DefaultFetcher's Provider if nil.
+func DarkenNight(provider Provider, dst draw.Image, t SatTime, factor float64) {
+	sunLat, sunLon := SubsolarPoint(t)
+
+	bounds := dst.Bounds()
+	projection := ProviderProjection(provider, bounds.Dx())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			lat, lon, ok := projection.ToLatLon(float64(x-bounds.Min.X)+0.5, float64(y-bounds.Min.Y)+0.5)
+			if !ok {
+				continue
+			}
+
+			e := solarElevation(sunLat, sunLon, lat, lon)
+			if e >= 0 {
+				continue
+			}
+
+			// 0 at sunset, 1 at the end of civil twilight
+			night := math.Min(1, e/CivilTwilight)
+			scale := 1 - night*(1-factor)
+
+			r, g, b, a := dst.At(x, y).RGBA()
+			dst.Set(x, y, color.RGBA64{
+				uint16(float64(r) * scale),
+				uint16(float64(g) * scale),
+				uint16(float64(b) * scale),
+				uint16(a),
+			})
+		}
+	}
+}
+
+// blendPixel draws c over the pixel at x, y.
+func blendPixel(dst draw.Image, x, y int, c color.Color) {
+	draw.Draw(dst, image.Rect(x, y, x+1, y+1), image.NewUniform(c), image.ZP, draw.Over)
+}
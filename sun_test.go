@@ -0,0 +1,114 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+	"time"
+)
+
+// TestSubsolarPoint tests the position of the sun at the equinox and
+// solstice.
+func TestSubsolarPoint(t *testing.T) {
+	subsolarTests := []struct {
+		name     string
+		in       time.Time
+		lat, lon float64
+	}{
+		// The equation of time puts the sun nearly 2° east of Greenwich at noon
+		{"March equinox", time.Date(2017, 3, 20, 12, 0, 0, 0, time.UTC), 0, 1.9},
+		{"June solstice", time.Date(2017, 6, 21, 0, 0, 0, 0, time.UTC), 23.44, 180},
+		{"December solstice", time.Date(2017, 12, 21, 6, 0, 0, 0, time.UTC), -23.44, 90},
+	}
+
+	for _, st := range subsolarTests {
+		t.Run(st.name, func(t *testing.T) {
+			lat, lon := SubsolarPoint(SatTime{st.in})
+
+			dLon := math.Mod(math.Abs(lon-st.lon), 360)
+			if math.Abs(lat-st.lat) > 0.6 || math.Min(dLon, 360-dLon) > 1 {
+				t.Errorf("Expected %v,%v, received %v,%v", st.lat, st.lon, lat, lon)
+			}
+		})
+	}
+}
+
+// TestDrawTerminator tests that night is only drawn on the night side.
+func TestDrawTerminator(t *testing.T) {
+	night := color.NRGBA{0, 0, 255, 255}
+	opts := TerminatorOptions{Night: night}
+
+	// Midday at the sub-satellite point, there is no night on the disk
+	noon := SatTime{time.Date(2017, 3, 20, 2, 40, 0, 0, time.UTC)}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	DrawTerminator(img, noon, opts)
+
+	if countColor(img, night) != 0 {
+		t.Errorf("Expected no night at midday")
+	}
+
+	// Midnight at the sub-satellite point, the centre is night
+	midnight := SatTime{time.Date(2017, 3, 20, 14, 40, 0, 0, time.UTC)}
+	img = image.NewRGBA(image.Rect(0, 0, 100, 100))
+	DrawTerminator(img, midnight, opts)
+
+	if img.RGBAAt(50, 50) != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("Expected night at the centre at midnight")
+	}
+}
+
+// TestDrawTerminatorWest tests that the terminator is drawn when night is
+// on the west half of the disk, to the left of the day side.
+func TestDrawTerminatorWest(t *testing.T) {
+	line := color.NRGBA{255, 0, 0, 255}
+	opts := TerminatorOptions{Line: line}
+
+	// Sunset at the sub-satellite point, the sun is 90° to the east
+	dusk := SatTime{time.Date(2017, 3, 20, 20, 45, 0, 0, time.UTC)}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	DrawTerminator(img, dusk, opts)
+
+	if n := countColor(img, line); n < 50 {
+		t.Errorf("Expected at least 50 terminator pixels, received %v", n)
+	}
+
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if img.RGBAAt(x, y) == (color.RGBA{255, 0, 0, 255}) && math.Abs(float64(x)-50) > 3 {
+				t.Fatalf("Expected the terminator near the centre column, received %v,%v", x, y)
+			}
+		}
+	}
+}
+
+// TestDarkenNight tests that only the night side is darkened.
+func TestDarkenNight(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.ZP, draw.Src)
+
+	midnight := SatTime{time.Date(2017, 3, 20, 14, 40, 0, 0, time.UTC)}
+	DarkenNight(nil, img, midnight, 0)
+
+	if c := img.RGBAAt(50, 50); c.R != 0 {
+		t.Errorf("Expected the centre to be dark, received %v", c)
+	}
+	if c := img.RGBAAt(0, 0); c.R != 255 {
+		t.Errorf("Expected space to be unchanged, received %v", c)
+	}
+}
+
+// countColor returns the number of pixels of colour c.
+func countColor(img image.Image, c color.Color) int {
+	count := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)) == color.NRGBAModel.Convert(c) {
+				count++
+			}
+		}
+	}
+	return count
+}
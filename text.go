@@ -0,0 +1,327 @@
+package himago
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Font provides glyphs for DrawText. BasicFont is built in, other fonts
+// such as a TrueType face can be adapted by implementing this interface.
+type Font interface {
+	// Glyph returns the coverage mask of r with its origin at the top left.
+	// The width of the mask is the advance of the glyph.
+	Glyph(r rune) *image.Alpha
+
+	// LineHeight returns the height of a line of text in pixels.
+	LineHeight() int
+}
+
+// BasicFont is an embedded 5x7 pixel bitmap font covering upper case
+// letters, digits and the punctuation used in captions. Lower case letters
+// are drawn in upper case and anything else as a question mark.
+type BasicFont struct {
+	// Scale multiplies the size of every pixel of the font. 0 is
+	// treated as 1.
+	Scale int
+}
+
+const (
+	basicGlyphWidth  = 5
+	basicGlyphHeight = 7
+)
+
+// basicGlyphs holds each glyph as 7 rows of 5 bits, most significant bit
+// on the left.
+var basicGlyphs = map[rune][basicGlyphHeight]uint8{
+	' ':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'#':  {0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a},
+	'%':  {0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03},
+	'\'': {0x04, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'(':  {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')':  {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'+':  {0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00},
+	',':  {0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08},
+	'-':  {0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00},
+	'.':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c},
+	'/':  {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'0':  {0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	'1':  {0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'2':  {0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	'3':  {0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	'4':  {0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	'5':  {0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	'6':  {0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	'7':  {0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8':  {0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	'9':  {0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+	':':  {0x00, 0x04, 0x04, 0x00, 0x04, 0x04, 0x00},
+	'?':  {0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'A':  {0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'B':  {0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e},
+	'C':  {0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e},
+	'D':  {0x1e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x1e},
+	'E':  {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f},
+	'F':  {0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10},
+	'G':  {0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f},
+	'H':  {0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11},
+	'I':  {0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	'J':  {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c},
+	'K':  {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L':  {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f},
+	'M':  {0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N':  {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O':  {0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'P':  {0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10},
+	'Q':  {0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d},
+	'R':  {0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11},
+	'S':  {0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e},
+	'T':  {0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e},
+	'V':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04},
+	'W':  {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a},
+	'X':  {0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11},
+	'Y':  {0x11, 0x11, 0x0a, 0x04, 0x04, 0x04, 0x04},
+	'Z':  {0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f},
+	'_':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f},
+	'°':  {0x0c, 0x12, 0x12, 0x0c, 0x00, 0x00, 0x00},
+	'µ':  {0x00, 0x00, 0x11, 0x11, 0x11, 0x13, 0x16},
+}
+
+func (f BasicFont) scale() int {
+	if f.Scale < 1 {
+		return 1
+	}
+
+	return f.Scale
+}
+
+// Glyph returns the mask of r, including one column of spacing on the right.
+func (f BasicFont) Glyph(r rune) *image.Alpha {
+	rows, ok := basicGlyphs[r]
+	if !ok {
+		rows, ok = basicGlyphs[unicode.ToUpper(r)]
+	}
+	if !ok {
+		rows = basicGlyphs['?']
+	}
+
+	s := f.scale()
+	mask := image.NewAlpha(image.Rect(0, 0, (basicGlyphWidth+1)*s, basicGlyphHeight*s))
+
+	for y, row := range rows {
+		for x := 0; x < basicGlyphWidth; x++ {
+			if row&(1<<uint(basicGlyphWidth-1-x)) == 0 {
+				continue
+			}
+			draw.Draw(mask, image.Rect(x*s, y*s, (x+1)*s, (y+1)*s), image.Opaque, image.ZP, draw.Src)
+		}
+	}
+
+	return mask
+}
+
+// LineHeight returns the height of the glyphs plus two pixels of spacing,
+// both scaled.
+func (f BasicFont) LineHeight() int {
+	return (basicGlyphHeight + 2) * f.scale()
+}
+
+// Anchor is the corner of an image that text is positioned relative to.
+type Anchor int
+
+// Corners that text can be anchored to.
+const (
+	TopLeft Anchor = iota
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// TextOptions configures DrawText.
+type TextOptions struct {
+	// Font defaults to BasicFont with a Scale of 2.
+	Font Font
+
+	// Color defaults to white.
+	Color color.Color
+
+	// Background, if not nil, is drawn behind the text to keep it legible.
+	Background color.Color
+
+	Anchor Anchor
+
+	// Margin is the distance in pixels from the edges of the image.
+	Margin int
+}
+
+func (o TextOptions) font() Font {
+	if o.Font != nil {
+		return o.Font
+	}
+
+	return BasicFont{Scale: 2}
+}
+
+func (o TextOptions) color() color.Color {
+	if o.Color != nil {
+		return o.Color
+	}
+
+	return color.White
+}
+
+// MeasureText returns the size of text drawn in font. Lines are separated
+// by "\n".
+func MeasureText(font Font, text string) image.Point {
+	lines := strings.Split(text, "\n")
+
+	var size image.Point
+	for _, line := range lines {
+		width := 0
+		for _, r := range line {
+			width += font.Glyph(r).Bounds().Dx()
+		}
+		if width > size.X {
+			size.X = width
+		}
+	}
+	size.Y = len(lines) * font.LineHeight()
+
+	return size
+}
+
+// DrawText draws text in a corner of dst.
+func DrawText(dst draw.Image, text string, opts TextOptions) {
+	font := opts.font()
+	size := MeasureText(font, text)
+	bounds := dst.Bounds()
+
+	origin := image.Pt(bounds.Min.X+opts.Margin, bounds.Min.Y+opts.Margin)
+	if opts.Anchor == TopRight || opts.Anchor == BottomRight {
+		origin.X = bounds.Max.X - opts.Margin - size.X
+	}
+	if opts.Anchor == BottomLeft || opts.Anchor == BottomRight {
+		origin.Y = bounds.Max.Y - opts.Margin - size.Y
+	}
+
+	drawTextAt(dst, origin, text, font, opts.color(), opts.Background)
+}
+
+// drawTextAt draws text with its top left corner at origin.
+func drawTextAt(dst draw.Image, origin image.Point, text string, font Font, c, background color.Color) {
+	if background != nil {
+		box := image.Rectangle{origin, origin.Add(MeasureText(font, text))}
+		draw.Draw(dst, box, image.NewUniform(background), image.ZP, draw.Over)
+	}
+
+	src := image.NewUniform(c)
+	for i, line := range strings.Split(text, "\n") {
+		p := origin.Add(image.Pt(0, i*font.LineHeight()))
+		for _, r := range line {
+			glyph := font.Glyph(r)
+			rect := glyph.Bounds().Add(p)
+			draw.DrawMask(dst, rect, src, image.ZP, glyph, image.ZP, draw.Over)
+			p.X += glyph.Bounds().Dx()
+		}
+	}
+}
+
+// Caption returns a caption for an image of band taken at t, shown in loc,
+// e.g. "2017-04-29 15:40 UTC  B13 CLEAN LONGWAVE WINDOW". loc defaults to UTC.
+func Caption(t SatTime, band Band, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	caption := t.In(loc).Format("2006-01-02 15:04 MST")
+
+	if info, ok := band.Info(); ok {
+		if band == Band(0) {
+			caption += "  " + info.Name
+		} else {
+			caption += fmt.Sprintf("  B%02d %s", band, info.Name)
+		}
+	}
+
+	return strings.ToUpper(caption)
+}
+
+// LegendOptions configures DrawLegend.
+type LegendOptions struct {
+	TextOptions
+
+	// Size of the colour bar in pixels. Defaults to 256x16 if either
+	// dimension is not positive.
+	Size image.Point
+
+	// Min and Max label the ends of the colour bar.
+	Min, Max string
+}
+
+// DrawLegend draws a horizontal colour bar of cm, labelled with opts.Min
+// at the left and opts.Max at the right, in a corner of dst.
+func DrawLegend(dst draw.Image, cm ColorMap, opts LegendOptions) {
+	size := opts.Size
+	if size.X <= 0 || size.Y <= 0 {
+		size = image.Pt(256, 16)
+	}
+
+	font := opts.font()
+	labels := opts.Min + " " + opts.Max
+	labelSize := MeasureText(font, labels)
+
+	width := size.X
+	if labelSize.X > width {
+		width = labelSize.X
+	}
+	height := size.Y + labelSize.Y
+
+	// Position the legend as a block using the same rules as DrawText
+	bounds := dst.Bounds()
+	origin := image.Pt(bounds.Min.X+opts.Margin, bounds.Min.Y+opts.Margin)
+	if opts.Anchor == TopRight || opts.Anchor == BottomRight {
+		origin.X = bounds.Max.X - opts.Margin - width
+	}
+	if opts.Anchor == BottomLeft || opts.Anchor == BottomRight {
+		origin.Y = bounds.Max.Y - opts.Margin - height
+	}
+
+	if opts.Background != nil {
+		box := image.Rect(origin.X, origin.Y, origin.X+width, origin.Y+height)
+		draw.Draw(dst, box, image.NewUniform(opts.Background), image.ZP, draw.Over)
+	}
+
+	for x := 0; x < size.X; x++ {
+		// A one pixel wide bar shows the low end rather than dividing by 0
+		pos := 0.0
+		if size.X > 1 {
+			pos = float64(x) / float64(size.X-1)
+		}
+
+		c := cm.At(pos)
+		column := image.Rect(origin.X+x, origin.Y, origin.X+x+1, origin.Y+size.Y)
+		draw.Draw(dst, column, image.NewUniform(c), image.ZP, draw.Over)
+	}
+
+	labelY := origin.Y + size.Y
+	drawTextAt(dst, image.Pt(origin.X, labelY), opts.Min, font, opts.color(), nil)
+
+	maxWidth := MeasureText(font, opts.Max).X
+	drawTextAt(dst, image.Pt(origin.X+width-maxWidth, labelY), opts.Max, font, opts.color(), nil)
+}
+
+// LegendLabels returns labels for the ends of a band's colour bar. Infrared
+// bands are labelled with their calibrated temperature in °C, other bands
+// with their relative brightness.
+func LegendLabels(band Band) (min, max string) {
+	if c, ok := band.Calibration(); ok {
+		return fmt.Sprintf("%.0f°C", Celsius(c.Warm)), fmt.Sprintf("%.0f°C", Celsius(c.Cold))
+	}
+
+	return "0%", "100%"
+}
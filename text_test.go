@@ -0,0 +1,91 @@
+package himago
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+// TestMeasureText tests the size of single and multi-line text.
+func TestMeasureText(t *testing.T) {
+	font := BasicFont{Scale: 2}
+
+	if size := MeasureText(font, "ABC"); size != image.Pt(36, 18) {
+		t.Errorf("Expected 36x18, received %v", size)
+	}
+
+	if size := MeasureText(font, "AB\nCDEF"); size != image.Pt(48, 36) {
+		t.Errorf("Expected 48x36, received %v", size)
+	}
+}
+
+// TestDrawText tests that text is drawn in the anchored corner only.
+func TestDrawText(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	white := color.RGBA{255, 255, 255, 255}
+
+	DrawText(img, "#", TextOptions{Color: white, Anchor: BottomRight, Font: BasicFont{}})
+
+	// The # glyph fills its middle row
+	if img.RGBAAt(97, 97) != white {
+		t.Errorf("Expected text in the bottom right corner")
+	}
+	if countColor(img.SubImage(image.Rect(0, 0, 50, 50)), white) != 0 {
+		t.Errorf("Expected nothing drawn in the top left corner")
+	}
+}
+
+// TestDrawTextZeroOptions tests that text is drawn in white when no
+// options are given.
+func TestDrawTextZeroOptions(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	DrawText(img, "x", TextOptions{})
+	if countColor(img, white) == 0 {
+		t.Errorf("Expected text drawn in white")
+	}
+
+	// The labels share the default, so this must not panic either
+	DrawLegend(image.NewRGBA(image.Rect(0, 0, 300, 100)), Greyscale, LegendOptions{Min: "x", Max: "x"})
+}
+
+// TestCaption tests captions in UTC and another timezone.
+func TestCaption(t *testing.T) {
+	st := SatTime{time.Date(2017, 4, 29, 15, 40, 0, 0, time.UTC)}
+
+	if caption := Caption(st, Band(13), nil); caption != "2017-04-29 15:40 UTC  B13 CLEAN LONGWAVE WINDOW" {
+		t.Errorf("Unexpected caption %q", caption)
+	}
+
+	jst := time.FixedZone("JST", 9*60*60)
+	if caption := Caption(st, Band(0), jst); caption != "2017-04-30 00:40 JST  TRUE COLOUR" {
+		t.Errorf("Unexpected caption %q", caption)
+	}
+}
+
+// TestLegendLabels tests that infrared bands are labelled in °C.
+func TestLegendLabels(t *testing.T) {
+	if min, max := LegendLabels(Band(13)); min != "47°C" || max != "-93°C" {
+		t.Errorf("Expected 47°C and -93°C, received %v and %v", min, max)
+	}
+
+	if min, max := LegendLabels(Band(3)); min != "0%" || max != "100%" {
+		t.Errorf("Expected 0%% and 100%%, received %v and %v", min, max)
+	}
+}
+
+// TestDrawLegendNarrow tests that a one pixel wide colour bar is drawn with
+// the low end of the colour map.
+func TestDrawLegendNarrow(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	red := color.NRGBA{255, 0, 0, 255}
+	cm := ColorMap{"red-blue", []ColorStop{{0, red}, {1, color.NRGBA{0, 0, 255, 255}}}}
+
+	DrawLegend(img, cm, LegendOptions{TextOptions: TextOptions{Font: BasicFont{}}, Size: image.Pt(1, 4)})
+
+	if c := img.RGBAAt(0, 0); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected %v, received %v", red, c)
+	}
+}
@@ -1,15 +1,14 @@
 package himago
 
 import (
-	"bytes"
 	"crypto/md5"
 	"fmt"
 	"image"
-	"image/png"
+	"image/draw"
 )
 
-// md5sum of a known bad image ("No Image")
-const noImageMD5 string = "b5fd2ee42ee01da39dbd477e9fe981cb"
+// md5sum of the pixels of a known bad image ("No Image")
+const noImageMD5 string = "93260861d94f280badcaa157fed7f99e"
 
 // Tile wraps an image.Image and provides helper functions to detect
 // "no image" images.
@@ -27,17 +26,23 @@ func (t *Tile) IsNoImage() bool {
 	return md5sum == noImageMD5
 }
 
-// md5Sum re-encodes the image as a PNG and returns the md5sum
-// of an image in hex format.
+// md5Sum returns the md5sum of the pixels of an image in hex format.
+// The pixels are hashed rather than a re-encoded PNG, whose bytes
+// depend on the version of the encoder.
 func (t *Tile) md5Sum() string {
-	var b bytes.Buffer
-	_ = png.Encode(&b, t.Image)
+	bounds := t.Bounds()
+	pixels := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(pixels, pixels.Bounds(), t.Image, bounds.Min, draw.Src)
 
 	// Convert to hex for comparison
-	return fmt.Sprintf("%x", md5.Sum(b.Bytes()))
+	return fmt.Sprintf("%x", md5.Sum(pixels.Pix))
 }
 
-func (t *Tile) setForeground(fg Color) {
+// withForeground returns a copy of the Tile recoloured to fg, keeping the
+// transparency of each pixel scaled by the alpha of fg. The Tile itself is
+// left unchanged so that it can be stitched again.
+func (t Tile) withForeground(fg Color) Tile {
+	alpha := uint32(fg.A)
 
 	new := image.NewRGBA(t.Bounds())
 
@@ -51,13 +56,12 @@ func (t *Tile) setForeground(fg Color) {
 
 			// Default foreground has alpha 255 (no transparency)
 			// Set the alpha channel to match the existing pixel
-			fg.A = uint8(a)
+			fg.A = uint8((a >> 8) * alpha / 255)
 
 			new.Set(x, y, fg)
 
 		}
 	}
 
-	t.Image = new.SubImage(t.Bounds())
-
+	return Tile{new.SubImage(t.Bounds())}
 }
@@ -0,0 +1,212 @@
+package himago
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadPaths loads the lines and polygon outlines of a local GeoJSON
+// (.geojson or .json) or ESRI shapefile (.shp) file, such as coastlines or
+// borders from Natural Earth. Coordinates must be longitude/latitude (WGS84).
+func LoadPaths(fileName string) ([]Path, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".geojson", ".json":
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		return parseGeoJSONPaths(data)
+	case ".shp":
+		f, err := os.Open(fileName)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return readShapefile(f, info.Size())
+	}
+
+	return nil, fmt.Errorf("unsupported vector file %v, expected .geojson or .shp", fileName)
+}
+
+// geoJSON holds the parts of any GeoJSON object that himago uses.
+type geoJSON struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSON        `json:"geometry"`
+	Geometries  []geoJSON       `json:"geometries"`
+	Features    []geoJSON       `json:"features"`
+	Properties  json.RawMessage `json:"properties"`
+}
+
+// parseGeoJSONPaths returns every line and polygon ring in a GeoJSON
+// document. Points are ignored.
+func parseGeoJSONPaths(data []byte) ([]Path, error) {
+	var obj geoJSON
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	var paths []Path
+	err := obj.walk(func(g *geoJSON) error {
+		var err error
+		switch g.Type {
+		case "LineString":
+			var line [][]float64
+			err = json.Unmarshal(g.Coordinates, &line)
+			paths = append(paths, pathFromCoordinates(line))
+		case "MultiLineString", "Polygon":
+			var lines [][][]float64
+			err = json.Unmarshal(g.Coordinates, &lines)
+			for _, line := range lines {
+				paths = append(paths, pathFromCoordinates(line))
+			}
+		case "MultiPolygon":
+			var polygons [][][][]float64
+			err = json.Unmarshal(g.Coordinates, &polygons)
+			for _, polygon := range polygons {
+				for _, line := range polygon {
+					paths = append(paths, pathFromCoordinates(line))
+				}
+			}
+		}
+		return err
+	})
+
+	return paths, err
+}
+
+// walk calls fn for every geometry within a FeatureCollection, Feature or
+// GeometryCollection. Geometries inherit the properties of their Feature.
+func (g *geoJSON) walk(fn func(*geoJSON) error) error {
+	switch g.Type {
+	case "FeatureCollection":
+		for i := range g.Features {
+			if err := g.Features[i].walk(fn); err != nil {
+				return err
+			}
+		}
+	case "Feature":
+		if g.Geometry == nil {
+			return nil
+		}
+		if g.Geometry.Properties == nil {
+			g.Geometry.Properties = g.Properties
+		}
+		return g.Geometry.walk(fn)
+	case "GeometryCollection":
+		for i := range g.Geometries {
+			if err := g.Geometries[i].walk(fn); err != nil {
+				return err
+			}
+		}
+	default:
+		return fn(g)
+	}
+
+	return nil
+}
+
+// pathFromCoordinates converts GeoJSON [lon, lat] positions to a Path.
+func pathFromCoordinates(coordinates [][]float64) Path {
+	path := make(Path, 0, len(coordinates))
+	for _, c := range coordinates {
+		if len(c) >= 2 {
+			path = append(path, LatLon{Lat: c[1], Lon: c[0]})
+		}
+	}
+	return path
+}
+
+// Shapefile shape types with parts of points, including the Z and M
+// variants which store their extra values after the points.
+var shapefileLineTypes = map[int32]bool{
+	3: true, 5: true, // PolyLine, Polygon
+	13: true, 15: true, // PolyLineZ, PolygonZ
+	23: true, 25: true, // PolyLineM, PolygonM
+}
+
+// readShapefile returns every line and polygon ring in the main (.shp)
+// file of an ESRI shapefile of size bytes. Other shape types are skipped.
+func readShapefile(r io.Reader, size int64) ([]Path, error) {
+	header := make([]byte, 100)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if binary.BigEndian.Uint32(header) != 9994 {
+		return nil, errors.New("not a shapefile")
+	}
+
+	var paths []Path
+	recordHeader := make([]byte, 8)
+	remaining := size - int64(len(header))
+
+	for {
+		if _, err := io.ReadFull(r, recordHeader); err == io.EOF {
+			return paths, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		// Lengths are in 16-bit words. They are checked against the size
+		// of the file so that a corrupt length cannot allocate more than
+		// the file holds.
+		length := int(binary.BigEndian.Uint32(recordHeader[4:])) * 2
+		remaining -= int64(len(recordHeader) + length)
+		if remaining < 0 {
+			return nil, errors.New("shapefile record is longer than the file")
+		}
+
+		record := make([]byte, length)
+		if _, err := io.ReadFull(r, record); err != nil {
+			return nil, err
+		}
+
+		if length < 44 || !shapefileLineTypes[int32(binary.LittleEndian.Uint32(record))] {
+			continue
+		}
+
+		// shape type (4), bounding box (32), number of parts and points
+		numParts := int(binary.LittleEndian.Uint32(record[36:]))
+		numPoints := int(binary.LittleEndian.Uint32(record[40:]))
+
+		partsStart := 44
+		pointsStart := partsStart + 4*numParts
+		if pointsStart+16*numPoints > length {
+			return nil, errors.New("truncated shapefile record")
+		}
+
+		for p := 0; p < numParts; p++ {
+			start := int(binary.LittleEndian.Uint32(record[partsStart+4*p:]))
+			end := numPoints
+			if p+1 < numParts {
+				end = int(binary.LittleEndian.Uint32(record[partsStart+4*(p+1):]))
+			}
+			if start < 0 || end > numPoints || start > end {
+				return nil, errors.New("invalid shapefile part")
+			}
+
+			path := make(Path, 0, end-start)
+			for i := start; i < end; i++ {
+				offset := pointsStart + 16*i
+				path = append(path, LatLon{
+					Lon: math.Float64frombits(binary.LittleEndian.Uint64(record[offset:])),
+					Lat: math.Float64frombits(binary.LittleEndian.Uint64(record[offset+8:])),
+				})
+			}
+			paths = append(paths, path)
+		}
+	}
+}
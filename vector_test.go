@@ -0,0 +1,83 @@
+package himago
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// TestParseGeoJSONPaths tests that lines and polygon rings are loaded.
+func TestParseGeoJSONPaths(t *testing.T) {
+	data := []byte(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[140, 35], [141, 36]]}},
+			{"type": "Feature", "geometry": {"type": "MultiPolygon", "coordinates": [[[[150, -30], [151, -30], [151, -31], [150, -30]]]]}},
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [140, 35]}}
+		]
+	}`)
+
+	paths, err := parseGeoJSONPaths(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 2 || len(paths[0]) != 2 || len(paths[1]) != 4 {
+		t.Fatalf("Unexpected paths %v", paths)
+	}
+
+	if paths[0][0] != (LatLon{35, 140}) {
+		t.Errorf("Expected coordinates in lon, lat order, received %v", paths[0][0])
+	}
+}
+
+// TestReadShapefile tests reading a shapefile with one two-part polyline.
+func TestReadShapefile(t *testing.T) {
+	points := [][2]float64{{140, 35}, {141, 36}, {150, -30}, {151, -31}}
+
+	var record bytes.Buffer
+	binary.Write(&record, binary.LittleEndian, int32(3))
+	binary.Write(&record, binary.LittleEndian, [4]float64{})
+	binary.Write(&record, binary.LittleEndian, [2]int32{2, int32(len(points))})
+	binary.Write(&record, binary.LittleEndian, [2]int32{0, 2})
+	for _, p := range points {
+		binary.Write(&record, binary.LittleEndian, p)
+	}
+
+	header := make([]byte, 100)
+	binary.BigEndian.PutUint32(header, 9994)
+
+	var shp bytes.Buffer
+	shp.Write(header)
+	binary.Write(&shp, binary.BigEndian, [2]int32{1, int32(record.Len() / 2)})
+	shp.Write(record.Bytes())
+
+	paths, err := readShapefile(&shp, int64(shp.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 2 || len(paths[0]) != 2 || len(paths[1]) != 2 {
+		t.Fatalf("Unexpected paths %v", paths)
+	}
+
+	if paths[1][1] != (LatLon{-31, 151}) {
+		t.Errorf("Expected -31,151, received %v", paths[1][1])
+	}
+}
+
+// TestReadShapefileLength tests that a record claiming to be longer than
+// the file is rejected before it is read.
+func TestReadShapefileLength(t *testing.T) {
+	header := make([]byte, 100)
+	binary.BigEndian.PutUint32(header, 9994)
+
+	var shp bytes.Buffer
+	shp.Write(header)
+	binary.Write(&shp, binary.BigEndian, [2]int32{1, 0x7fffffff})
+	shp.Write(make([]byte, 44))
+
+	if _, err := readShapefile(&shp, int64(shp.Len())); err == nil {
+		t.Error("Expected an error for a record longer than the file")
+	}
+}
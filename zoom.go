@@ -42,3 +42,12 @@ func (z *Zoom) Set(zoomString string) error {
 func (z *Zoom) GridWidth() int {
 	return int(math.Pow(2, float64(*z-1)))
 }
+
+// zoomForGridWidth is the inverse of GridWidth.
+func zoomForGridWidth(gridWidth int) Zoom {
+	if gridWidth < 1 {
+		return 0
+	}
+
+	return Zoom(math.Round(math.Log2(float64(gridWidth)))) + 1
+}